@@ -2,12 +2,17 @@ package main
 
 import (
 	"log"
+	"os"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
 	"github.com/gratefultolord/ac_signup_bot/internal/adminbot"
+	"github.com/gratefultolord/ac_signup_bot/internal/adminnotify"
+	"github.com/gratefultolord/ac_signup_bot/internal/catalog"
 	"github.com/gratefultolord/ac_signup_bot/internal/config"
 	"github.com/gratefultolord/ac_signup_bot/internal/db"
+	"github.com/gratefultolord/ac_signup_bot/internal/events"
 	"github.com/gratefultolord/ac_signup_bot/internal/files"
 )
 
@@ -23,6 +28,19 @@ func main() {
 	}
 	defer database.Close()
 
+	migrator := db.NewMigrator(database.Conn, "db_scripts/migrations")
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := migrator.Run(os.Args[2:]); err != nil {
+			log.Fatalf("Error running migrate: %v\n", err)
+		}
+		return
+	}
+
+	if err := migrator.Up(); err != nil {
+		log.Fatalf("Error running migrations: %v\n", err)
+	}
+
 	botApi, err := tgbotapi.NewBotAPI(cfg.AdminBotToken)
 	if err != nil {
 		log.Fatalf("Error creating Telegram bot: %v\n", err)
@@ -32,22 +50,52 @@ func main() {
 	userRepo := db.NewUsersRepository(database.Conn)
 	tokenRepo := db.NewTokenRepository(database.Conn)
 	adminRepo := db.NewAdminRepository(database.Conn)
+	categoryRepo := db.NewCategoryRepository(database.Conn)
+	partnerRepo := db.NewPartnerRepository(database.Conn)
+	supportRepo := db.NewSupportRepository(database.Conn)
+	outboxRepo := db.NewOutboxRepository(database.Conn)
+	reasonRepo := db.NewReasonTemplateRepository(database.Conn)
+	auditRepo := db.NewAuditRepository(database.Conn)
 
 	fileService, err := files.NewFileService(botApi, "doc_files")
 	if err != nil {
 		log.Fatalf("Error creating FileService: %v\n", err)
 	}
 
+	photoService, err := files.NewFileService(botApi, catalog.PhotoDir)
+	if err != nil {
+		log.Fatalf("Error creating FileService: %v\n", err)
+	}
+
 	adminBotService := adminbot.New(
 		botApi,
 		registrationRepo,
 		userRepo,
 		tokenRepo,
 		adminRepo,
+		categoryRepo,
+		partnerRepo,
+		supportRepo,
+		outboxRepo,
+		reasonRepo,
+		auditRepo,
 		fileService,
+		photoService,
+		events.NewPublisher(database.Conn),
+		adminnotify.New(botApi, adminRepo, cfg.AdminNotifyWindow),
+		cfg.RequestClaimTTL,
+		cfg.AdminInviteTTL,
 	)
 
+	go adminBotService.RunHoldReminders(time.Minute)
+
 	log.Printf("Admin bot started as @%s\n", botApi.Self.UserName)
 
-	adminBotService.Start(cfg.BotToken)
+	listener, err := events.Listen(db.DSN(cfg))
+	if err != nil {
+		log.Fatalf("Error listening for events: %v\n", err)
+	}
+	defer listener.Close()
+
+	adminBotService.Start(listener.Events())
 }
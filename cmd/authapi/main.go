@@ -0,0 +1,51 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gratefultolord/ac_signup_bot/internal/authapi"
+	"github.com/gratefultolord/ac_signup_bot/internal/config"
+	"github.com/gratefultolord/ac_signup_bot/internal/db"
+)
+
+func main() {
+	cfg, err := config.LoadAuthAPI()
+	if err != nil {
+		log.Fatalf("Error loading config: %v", err)
+	}
+
+	database, err := db.New(cfg)
+	if err != nil {
+		log.Fatalf("Error connecting to database: %v", err)
+	}
+	defer database.Close()
+
+	signer, err := authapi.NewSigner(cfg)
+	if err != nil {
+		log.Fatalf("Error creating JWT signer: %v", err)
+	}
+
+	tokenRepo := db.NewTokenRepository(database.Conn)
+	userRepo := db.NewUsersRepository(database.Conn)
+
+	server := authapi.New(
+		tokenRepo,
+		userRepo,
+		signer,
+		authapi.NewAttemptLimiter(cfg.AuthMaxAttempts, cfg.AuthLockout),
+	)
+
+	httpServer := &http.Server{
+		Addr:              cfg.AuthAPIAddr,
+		Handler:           server.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	log.Printf("Auth API listening on %s", cfg.AuthAPIAddr)
+
+	if err := httpServer.ListenAndServe(); err != nil {
+		log.Fatalf("Auth API stopped: %v", err)
+	}
+}
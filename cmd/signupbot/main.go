@@ -2,6 +2,8 @@ package main
 
 import (
 	"log"
+	"os"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	_ "github.com/lib/pq"
@@ -9,6 +11,7 @@ import (
 	"github.com/gratefultolord/ac_signup_bot/internal/bot"
 	"github.com/gratefultolord/ac_signup_bot/internal/config"
 	"github.com/gratefultolord/ac_signup_bot/internal/db"
+	"github.com/gratefultolord/ac_signup_bot/internal/events"
 	"github.com/gratefultolord/ac_signup_bot/internal/files"
 )
 
@@ -24,8 +27,16 @@ func main() {
 	}
 	defer database.Close()
 
-	err = db.RunMigrations(database.Conn, "db_scripts/init.sql", "db_scripts/admin.sql")
-	if err != nil {
+	migrator := db.NewMigrator(database.Conn, "db_scripts/migrations")
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := migrator.Run(os.Args[2:]); err != nil {
+			log.Fatalf("Error running migrate: %v", err)
+		}
+		return
+	}
+
+	if err := migrator.Up(); err != nil {
 		log.Fatalf("Error running migrations: %v", err)
 	}
 
@@ -37,7 +48,13 @@ func main() {
 	registrationRepo := db.NewRegistrationRequestRepository(database.Conn)
 	userRepo := db.NewUsersRepository(database.Conn)
 	adminRepo := db.NewAdminRepository(database.Conn)
-	tokenRepo := db.NewTokenRepository(database.Conn)
+	paymentRepo := db.NewPaymentRepository(database.Conn)
+	userStateRepo := db.NewUserStateRepository(database.Conn)
+	subscriptionRepo := db.NewSubscriptionRepository(database.Conn)
+	categoryRepo := db.NewCategoryRepository(database.Conn)
+	partnerRepo := db.NewPartnerRepository(database.Conn)
+	supportRepo := db.NewSupportRepository(database.Conn)
+	outboxRepo := db.NewOutboxRepository(database.Conn)
 
 	fileService, err := files.NewFileService(botAPI, "doc_files")
 	if err != nil {
@@ -48,13 +65,30 @@ func main() {
 		botAPI,
 		registrationRepo,
 		userRepo,
-		tokenRepo,
+		paymentRepo,
 		adminRepo,
+		subscriptionRepo,
+		categoryRepo,
+		partnerRepo,
+		supportRepo,
+		outboxRepo,
 		fileService,
+		bot.NewPostgresStateStore(userStateRepo),
+		events.NewPublisher(database.Conn),
 		cfg.TelegramProviderToken,
+		bot.NewTariffs(cfg.SubscriptionPrice, cfg.SubscriptionCurrency),
 	)
 
+	go botService.RunSubscriptionScheduler(time.Hour, cfg.SubscriptionGrace)
+	go botService.RunOutboxDispatcher(time.Minute)
+
 	log.Printf("Bot started as @%s", botAPI.Self.UserName)
 
-	botService.Start()
+	listener, err := events.Listen(db.DSN(cfg))
+	if err != nil {
+		log.Fatalf("Error listening for events: %v", err)
+	}
+	defer listener.Close()
+
+	botService.Start(listener.Events())
 }
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS partners;
+DROP TABLE IF EXISTS categories;
+DROP TABLE IF EXISTS admin_messages;
+DROP TABLE IF EXISTS admins;
+DROP TABLE IF EXISTS tokens;
+DROP TABLE IF EXISTS registration_requests;
+DROP TABLE IF EXISTS users;
@@ -1,13 +1,5 @@
-DROP TABLE IF EXISTS admin_messages CASCADE;
-DROP TABLE IF EXISTS registration_requests CASCADE;
-DROP TABLE IF EXISTS tokens CASCADE;
-DROP TABLE IF EXISTS users CASCADE;
-DROP TABLE IF EXISTS admins CASCADE;
-DROP TABLE IF EXISTS categories CASCADE;
-DROP TABLE IF EXISTS partners CASCADE;
-
 -- Таблица для хранения пользователей
-CREATE TABLE users (
+CREATE TABLE IF NOT EXISTS users (
                        id SERIAL PRIMARY KEY,
                        telegram_user_id BIGINT UNIQUE,
                        first_name VARCHAR(255) NOT NULL,
@@ -22,7 +14,7 @@ CREATE TABLE users (
 );
 
 -- Таблица для хранения заявок на регистрацию
-CREATE TABLE registration_requests (
+CREATE TABLE IF NOT EXISTS registration_requests (
                                        id SERIAL PRIMARY KEY,
                                        user_id INT, -- Ссылка на пользователя (заполняется после одобрения и оплаты подписки)
                                        telegram_user_id BIGINT, -- Telegram ID пользователя
@@ -32,14 +24,15 @@ CREATE TABLE registration_requests (
                                        user_status VARCHAR(50) NOT NULL CHECK (user_status IN ('student', 'employee', 'graduate')),
                                        document_path VARCHAR(255), -- Путь к документу (например, doc_files/xxx.jpg)
                                        phone_number VARCHAR(20) NOT NULL,
-                                       status VARCHAR(20) NOT NULL CHECK (status IN ('pending', 'approved', 'rejected')),
+                                       status VARCHAR(20) NOT NULL,
+                                       rejection_reason TEXT,
                                        created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
                                        updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
                                        CONSTRAINT fk_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL
 );
 
 -- Таблица для хранения кодов входа
-CREATE TABLE tokens (
+CREATE TABLE IF NOT EXISTS tokens (
                         id SERIAL PRIMARY KEY,
                         user_id INT NOT NULL,
                         token VARCHAR(255) UNIQUE, -- JWT
@@ -50,14 +43,14 @@ CREATE TABLE tokens (
 );
 
 -- Таблица для хранения администраторов
-CREATE TABLE admins (
+CREATE TABLE IF NOT EXISTS admins (
                         id SERIAL PRIMARY KEY,
                         chat_id BIGINT NOT NULL UNIQUE, -- Telegram Chat ID админа
                         created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
 
 -- Create admin_messages table
-CREATE TABLE admin_messages (
+CREATE TABLE IF NOT EXISTS admin_messages (
                                 id SERIAL PRIMARY KEY,
                                 telegram_user_id BIGINT NOT NULL,
                                 first_name VARCHAR(255) NOT NULL,
@@ -66,7 +59,7 @@ CREATE TABLE admin_messages (
                                 created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
-CREATE TABLE categories (
+CREATE TABLE IF NOT EXISTS categories (
     id SERIAL PRIMARY KEY,
     title VARCHAR(255) NOT NULL,
     photo_path VARCHAR(255),
@@ -74,7 +67,7 @@ CREATE TABLE categories (
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
 
-CREATE TABLE partners (
+CREATE TABLE IF NOT EXISTS partners (
     id SERIAL PRIMARY KEY,
     category_id INT NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
     title VARCHAR(255) NOT NULL,
@@ -90,11 +83,11 @@ CREATE TABLE partners (
 );
 
 -- Индексы для оптимизации
-CREATE INDEX idx_registration_requests_status ON registration_requests(status);
-CREATE INDEX idx_tokens_user_id ON tokens(user_id);
-CREATE INDEX idx_admins_chat_id ON admins(chat_id);
+CREATE INDEX IF NOT EXISTS idx_registration_requests_status ON registration_requests(status);
+CREATE INDEX IF NOT EXISTS idx_tokens_user_id ON tokens(user_id);
+CREATE INDEX IF NOT EXISTS idx_admins_chat_id ON admins(chat_id);
 
-ALTER TABLE registration_requests DROP CONSTRAINT registration_requests_status_check;
+-- Базы, созданные старым init.sql, уже содержат эти объекты: приводим их к одному виду
+ALTER TABLE registration_requests ADD COLUMN IF NOT EXISTS rejection_reason TEXT;
+ALTER TABLE registration_requests DROP CONSTRAINT IF EXISTS registration_requests_status_check;
 ALTER TABLE registration_requests ADD CONSTRAINT registration_requests_status_check CHECK (status IN ('pending', 'approved', 'rejected', 'on_hold', 'needs_revision'));
-
-ALTER TABLE registration_requests ADD COLUMN rejection_reason TEXT;
\ No newline at end of file
@@ -0,0 +1,8 @@
+DELETE FROM partners
+WHERE title IN ('Кинза и базилик', 'Lo Vegan', 'Chocoroom', 'Pankoff Bakery');
+
+DELETE FROM categories
+WHERE title IN ('Еда', 'Продукты', 'Красота', 'Здоровье', 'Путешествия', 'Другое')
+AND NOT EXISTS (SELECT 1 FROM partners WHERE partners.category_id = categories.id);
+
+DELETE FROM admins WHERE chat_id IN (166018759, 320522635);
@@ -0,0 +1,25 @@
+INSERT INTO admins (chat_id) VALUES (166018759), (320522635)
+ON CONFLICT (chat_id) DO NOTHING;
+
+INSERT INTO categories (title, photo_path, created_at, updated_at)
+SELECT v.title, v.photo_path, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP
+FROM (VALUES
+    ('Еда', 'food.png'),
+    ('Продукты', 'groceries.png'),
+    ('Красота', 'beauty.png'),
+    ('Здоровье', 'health.png'),
+    ('Путешествия', 'travel.png'),
+    ('Другое', 'other.png')
+) AS v(title, photo_path)
+WHERE NOT EXISTS (SELECT 1 FROM categories);
+
+INSERT INTO partners (category_id, title, description, address, url, photo_path, discount_type, discount_percent_size, created_at, updated_at)
+SELECT c.id, v.title, v.description, v.address, v.url, v.photo_path, 'percent', v.discount, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP
+FROM (VALUES
+    ('Кинза и базилик', 'Аутентичные блюда: приготовленные с мастерством и знанием дела, уют и гостеприимство, радость душевных встреч и благодарные отзывы любимых гостей', 'Мельковская ул. 2Д', 'kinzabazilik.ru', 'kinza_bazilik.png', 15),
+    ('Lo Vegan', 'Современное веган-кафе с авторским подходом к полезной еде и уютной атмосферой. Отличный выбор для тех, кто заботится о себе.', 'ул. Добролюбова, 19', 'lovegan.ru', 'lo_vegan.png', 10),
+    ('Chocoroom', 'Арт-кофейня и десерт-бар, где шоколад становится искусством. Ручная работа, премиальные ингредиенты и стильный интерьер.', 'ул. Большая Покровская, 45', 'chocoroom.ru', 'chocoroom.png', 12),
+    ('Pankoff Bakery', 'Семейная пекарня с настоящей душой: хрустящий хлеб, нежные булочки и кофе, который согревает. Свежее каждый день.', 'ул. Октябрьская, 8', 'pankoffbakery.ru', 'pankoff_bakery.png', 8)
+) AS v(title, description, address, url, photo_path, discount)
+JOIN categories c ON c.title = 'Еда'
+WHERE NOT EXISTS (SELECT 1 FROM partners);
@@ -0,0 +1,7 @@
+-- Состояние незавершённой регистрации, переживает перезапуск бота
+CREATE TABLE IF NOT EXISTS user_states (
+    chat_id BIGINT PRIMARY KEY,
+    step VARCHAR(64) NOT NULL,
+    data JSONB NOT NULL DEFAULT '{}'::jsonb,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1 @@
+ALTER TABLE registration_requests DROP COLUMN IF EXISTS revision_count;
@@ -0,0 +1,2 @@
+-- Сколько раз заявка возвращалась пользователем после доработки
+ALTER TABLE registration_requests ADD COLUMN IF NOT EXISTS revision_count INT NOT NULL DEFAULT 0;
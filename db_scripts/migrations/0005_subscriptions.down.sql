@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS subscription_reminders;
+DROP INDEX IF EXISTS idx_users_expires_at;
+ALTER TABLE users DROP COLUMN IF EXISTS membership_status;
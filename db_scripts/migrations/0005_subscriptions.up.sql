@@ -0,0 +1,14 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS membership_status VARCHAR(20) NOT NULL DEFAULT 'active'
+    CHECK (membership_status IN ('active', 'expired'));
+
+CREATE INDEX IF NOT EXISTS idx_users_expires_at ON users(expires_at);
+
+-- Отправленные напоминания об окончании подписки, чтобы не дублировать их
+CREATE TABLE IF NOT EXISTS subscription_reminders (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL, -- срок подписки, о котором напоминали
+    days_before INT NOT NULL,
+    sent_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, expires_at, days_before)
+);
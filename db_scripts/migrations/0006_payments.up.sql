@@ -0,0 +1,16 @@
+-- Журнал платежей: одна строка на каждый SuccessfulPayment
+CREATE TABLE IF NOT EXISTS payments (
+    id SERIAL PRIMARY KEY,
+    user_id INT REFERENCES users(id) ON DELETE SET NULL,
+    telegram_user_id BIGINT NOT NULL,
+    telegram_charge_id VARCHAR(255) NOT NULL UNIQUE, -- повторная доставка апдейта не создаст второй платёж
+    provider_charge_id VARCHAR(255) NOT NULL,
+    amount BIGINT NOT NULL, -- в минимальных единицах валюты (копейках)
+    currency VARCHAR(3) NOT NULL,
+    payload VARCHAR(255) NOT NULL,
+    tariff VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_payments_telegram_user_id ON payments(telegram_user_id);
+CREATE INDEX IF NOT EXISTS idx_payments_provider_charge_id ON payments(provider_charge_id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_tokens_phone_number;
+-- Длинные JWT не помещаются в прежний тип, такие сессии придётся открыть заново
+UPDATE tokens SET token = NULL WHERE length(token) > 255;
+ALTER TABLE tokens ALTER COLUMN token TYPE VARCHAR(255);
+ALTER TABLE tokens DROP COLUMN IF EXISTS expires_at;
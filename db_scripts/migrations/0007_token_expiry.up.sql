@@ -0,0 +1,7 @@
+-- Срок действия выданного приложению JWT
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP WITH TIME ZONE;
+
+-- JWT с подписью RS256 длиннее 255 символов
+ALTER TABLE tokens ALTER COLUMN token TYPE TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_tokens_phone_number ON tokens(phone_number);
@@ -0,0 +1,4 @@
+ALTER TABLE partners DROP CONSTRAINT IF EXISTS partners_discount_check;
+DROP INDEX IF EXISTS idx_partners_category_id;
+ALTER TABLE partners DROP COLUMN IF EXISTS sort_order;
+ALTER TABLE categories DROP COLUMN IF EXISTS sort_order;
@@ -0,0 +1,13 @@
+-- Порядок показа в каталоге: сначала по sort_order, затем по названию
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS sort_order INT NOT NULL DEFAULT 0;
+ALTER TABLE partners ADD COLUMN IF NOT EXISTS sort_order INT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_partners_category_id ON partners(category_id);
+
+-- Размер скидки должен соответствовать её типу
+ALTER TABLE partners DROP CONSTRAINT IF EXISTS partners_discount_check;
+ALTER TABLE partners ADD CONSTRAINT partners_discount_check CHECK (
+    (discount_type IS NULL AND discount_percent_size IS NULL AND discount_fixed_size IS NULL)
+    OR (discount_type = 'percent' AND discount_percent_size > 0 AND discount_percent_size <= 100 AND discount_fixed_size IS NULL)
+    OR (discount_type = 'fixed' AND discount_fixed_size > 0 AND discount_percent_size IS NULL)
+);
@@ -0,0 +1,2 @@
+ALTER TABLE partners DROP COLUMN IF EXISTS is_published;
+ALTER TABLE categories DROP COLUMN IF EXISTS is_published;
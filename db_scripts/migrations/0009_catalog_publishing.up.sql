@@ -0,0 +1,3 @@
+-- Скрытые категории и неопубликованные партнёры не видны участникам
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS is_published BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE partners ADD COLUMN IF NOT EXISTS is_published BOOLEAN NOT NULL DEFAULT TRUE;
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS admin_messages (
+                                id SERIAL PRIMARY KEY,
+                                telegram_user_id BIGINT NOT NULL,
+                                first_name VARCHAR(255) NOT NULL,
+                                last_name VARCHAR(255) NOT NULL,
+                                message TEXT NOT NULL,
+                                created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO admin_messages (telegram_user_id, first_name, last_name, message, created_at)
+SELECT c.telegram_user_id, c.first_name, c.last_name, m.text, m.created_at
+FROM support_messages m
+JOIN support_conversations c ON c.id = m.conversation_id
+WHERE m.sender = 'user';
+
+DROP TABLE IF EXISTS support_messages;
+DROP TABLE IF EXISTS support_conversations;
@@ -0,0 +1,47 @@
+-- Обращения пользователей к администраторам: диалог и его сообщения
+CREATE TABLE IF NOT EXISTS support_conversations (
+    id SERIAL PRIMARY KEY,
+    telegram_user_id BIGINT NOT NULL,
+    first_name VARCHAR(255) NOT NULL,
+    last_name VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open'
+        CHECK (status IN ('open', 'answered', 'closed')),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- У пользователя не больше одного незакрытого обращения
+CREATE UNIQUE INDEX IF NOT EXISTS support_conversations_active_idx
+    ON support_conversations (telegram_user_id)
+    WHERE status <> 'closed';
+
+CREATE INDEX IF NOT EXISTS support_conversations_status_idx
+    ON support_conversations (status, updated_at);
+
+CREATE TABLE IF NOT EXISTS support_messages (
+    id SERIAL PRIMARY KEY,
+    conversation_id INT NOT NULL REFERENCES support_conversations(id) ON DELETE CASCADE,
+    sender VARCHAR(10) NOT NULL CHECK (sender IN ('user', 'admin')),
+    admin_chat_id BIGINT,
+    text TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS support_messages_conversation_idx
+    ON support_messages (conversation_id, created_at);
+
+-- Старые сообщения админу переносим в открытые обращения
+INSERT INTO support_conversations (telegram_user_id, first_name, last_name, status, created_at, updated_at)
+SELECT DISTINCT ON (telegram_user_id)
+    telegram_user_id, first_name, last_name, 'open',
+    MIN(created_at) OVER (PARTITION BY telegram_user_id),
+    MAX(created_at) OVER (PARTITION BY telegram_user_id)
+FROM admin_messages
+ORDER BY telegram_user_id, created_at DESC;
+
+INSERT INTO support_messages (conversation_id, sender, text, created_at)
+SELECT c.id, 'user', m.message, COALESCE(m.created_at, CURRENT_TIMESTAMP)
+FROM admin_messages m
+JOIN support_conversations c ON c.telegram_user_id = m.telegram_user_id;
+
+DROP TABLE IF EXISTS admin_messages;
@@ -0,0 +1,2 @@
+ALTER TABLE admins DROP COLUMN IF EXISTS notify_messages;
+ALTER TABLE admins DROP COLUMN IF EXISTS notify_requests;
@@ -0,0 +1,3 @@
+-- Какие push-уведомления админ получает в боте администратора
+ALTER TABLE admins ADD COLUMN IF NOT EXISTS notify_requests BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE admins ADD COLUMN IF NOT EXISTS notify_messages BOOLEAN NOT NULL DEFAULT TRUE;
@@ -0,0 +1,20 @@
+-- Уведомления пользователям о решении по заявке. Пишутся в одной транзакции
+-- со сменой статуса и доставляются ботом регистрации с повторами
+CREATE TABLE IF NOT EXISTS notification_outbox (
+    id SERIAL PRIMARY KEY,
+    kind VARCHAR(32) NOT NULL,
+    telegram_user_id BIGINT NOT NULL,
+    request_id INT REFERENCES registration_requests(id) ON DELETE CASCADE,
+    reason TEXT,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'sent', 'dead')),
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    sent_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS notification_outbox_due_idx
+    ON notification_outbox (next_attempt_at)
+    WHERE status = 'pending';
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS registration_requests_pending_idx;
+ALTER TABLE registration_requests DROP COLUMN IF EXISTS claimed_at;
+ALTER TABLE registration_requests DROP COLUMN IF EXISTS claimed_by;
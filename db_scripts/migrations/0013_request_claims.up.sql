@@ -0,0 +1,7 @@
+-- Заявку, которую открыл админ, другие админы не видят, пока закрепление не истечёт
+ALTER TABLE registration_requests ADD COLUMN IF NOT EXISTS claimed_by INT REFERENCES admins(id) ON DELETE SET NULL;
+ALTER TABLE registration_requests ADD COLUMN IF NOT EXISTS claimed_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS registration_requests_pending_idx
+    ON registration_requests (created_at)
+    WHERE status = 'pending';
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS registration_request_events;
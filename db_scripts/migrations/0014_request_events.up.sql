@@ -0,0 +1,14 @@
+-- История смены статусов заявки: кто, когда и с какой причиной
+CREATE TABLE IF NOT EXISTS registration_request_events (
+    id SERIAL PRIMARY KEY,
+    request_id INT NOT NULL REFERENCES registration_requests(id) ON DELETE CASCADE,
+    actor_type VARCHAR(16) NOT NULL CHECK (actor_type IN ('admin', 'user')),
+    actor_chat_id BIGINT NOT NULL,
+    old_status VARCHAR(32),
+    new_status VARCHAR(32) NOT NULL,
+    reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS registration_request_events_request_idx
+    ON registration_request_events (request_id, created_at);
@@ -0,0 +1,5 @@
+UPDATE registration_requests SET status = 'approved' WHERE status = 'paid';
+
+ALTER TABLE registration_requests DROP CONSTRAINT IF EXISTS registration_requests_status_check;
+ALTER TABLE registration_requests ADD CONSTRAINT registration_requests_status_check
+    CHECK (status IN ('pending', 'approved', 'rejected', 'on_hold', 'needs_revision'));
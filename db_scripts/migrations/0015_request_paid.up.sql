@@ -0,0 +1,11 @@
+-- Оплаченная заявка получает отдельный конечный статус
+ALTER TABLE registration_requests DROP CONSTRAINT IF EXISTS registration_requests_status_check;
+ALTER TABLE registration_requests ADD CONSTRAINT registration_requests_status_check
+    CHECK (status IN ('pending', 'approved', 'rejected', 'on_hold', 'needs_revision', 'paid'));
+
+-- Участник появляется в users только после оплаты. user_id у заявок заполняется
+-- с недавних пор, поэтому старые оплаченные заявки находим по telegram_user_id
+UPDATE registration_requests r
+SET status = 'paid', user_id = COALESCE(r.user_id, u.id)
+FROM users u
+WHERE r.status = 'approved' AND u.telegram_user_id = r.telegram_user_id;
@@ -0,0 +1,11 @@
+DELETE FROM registration_request_events WHERE actor_type = 'system';
+ALTER TABLE registration_request_events DROP CONSTRAINT IF EXISTS registration_request_events_actor_type_check;
+ALTER TABLE registration_request_events ADD CONSTRAINT registration_request_events_actor_type_check
+    CHECK (actor_type IN ('admin', 'user'));
+
+UPDATE registration_requests SET status = 'pending' WHERE status = 'on_hold';
+
+DROP INDEX IF EXISTS registration_requests_remind_idx;
+ALTER TABLE registration_requests DROP COLUMN IF EXISTS remind_at;
+ALTER TABLE registration_requests DROP COLUMN IF EXISTS hold_note;
+ALTER TABLE registration_requests DROP COLUMN IF EXISTS held_by;
@@ -0,0 +1,13 @@
+-- Отложенная заявка: кто отложил, внутренняя заметка и время напоминания
+ALTER TABLE registration_requests ADD COLUMN IF NOT EXISTS held_by INT REFERENCES admins(id) ON DELETE SET NULL;
+ALTER TABLE registration_requests ADD COLUMN IF NOT EXISTS hold_note TEXT;
+ALTER TABLE registration_requests ADD COLUMN IF NOT EXISTS remind_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS registration_requests_remind_idx
+    ON registration_requests (remind_at)
+    WHERE status = 'on_hold';
+
+-- Напоминание возвращает заявку в работу без участия админа
+ALTER TABLE registration_request_events DROP CONSTRAINT IF EXISTS registration_request_events_actor_type_check;
+ALTER TABLE registration_request_events ADD CONSTRAINT registration_request_events_actor_type_check
+    CHECK (actor_type IN ('admin', 'user', 'system'));
@@ -0,0 +1,11 @@
+-- Внутренние заметки админов к заявке, пользователю не показываются
+CREATE TABLE IF NOT EXISTS registration_request_notes (
+    id SERIAL PRIMARY KEY,
+    request_id INT NOT NULL REFERENCES registration_requests(id) ON DELETE CASCADE,
+    admin_chat_id BIGINT NOT NULL,
+    text TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS registration_request_notes_request_idx
+    ON registration_request_notes (request_id, created_at);
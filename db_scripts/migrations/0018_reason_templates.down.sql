@@ -0,0 +1,3 @@
+ALTER TABLE registration_request_events DROP COLUMN IF EXISTS template_id;
+
+DROP TABLE IF EXISTS reason_templates;
@@ -0,0 +1,20 @@
+-- Готовые причины отклонения и отправки на доработку
+CREATE TABLE IF NOT EXISTS reason_templates (
+    id SERIAL PRIMARY KEY,
+    kind VARCHAR(32) NOT NULL CHECK (kind IN ('rejected', 'needs_revision')),
+    text TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- По шаблону в истории заявки считается статистика использования
+ALTER TABLE registration_request_events
+    ADD COLUMN IF NOT EXISTS template_id INT REFERENCES reason_templates(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS registration_request_events_template_idx
+    ON registration_request_events (template_id)
+    WHERE template_id IS NOT NULL;
+
+INSERT INTO reason_templates (kind, text) VALUES
+    ('needs_revision', 'Документ нечитаем'),
+    ('rejected', 'Не подтверждена принадлежность к МГИМО');
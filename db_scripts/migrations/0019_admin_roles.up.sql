@@ -0,0 +1,6 @@
+-- Роль админа определяет доступные ему разделы бота администратора.
+-- Существующие админы имели полный доступ, поэтому становятся владельцами
+ALTER TABLE admins ADD COLUMN IF NOT EXISTS role VARCHAR(32) NOT NULL DEFAULT 'owner'
+    CHECK (role IN ('owner', 'moderator', 'support', 'partner_manager'));
+
+ALTER TABLE admins ALTER COLUMN role SET DEFAULT 'moderator';
@@ -0,0 +1,11 @@
+-- Одноразовые приглашения в бот администратора. Хранится только хеш токена из ссылки
+CREATE TABLE IF NOT EXISTS admin_invites (
+    id SERIAL PRIMARY KEY,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    role VARCHAR(32) NOT NULL CHECK (role IN ('owner', 'moderator', 'support', 'partner_manager')),
+    invited_by INT NOT NULL REFERENCES admins(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_by_chat_id BIGINT,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,26 @@
+-- Журнал действий админов. Ссылки на admins нет: записи остаются после удаления админа
+CREATE TABLE IF NOT EXISTS admin_audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    admin_chat_id BIGINT NOT NULL,
+    action VARCHAR(32) NOT NULL,
+    target_type VARCHAR(32),
+    target_id BIGINT,
+    details TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS admin_audit_log_created_idx ON admin_audit_log (created_at);
+CREATE INDEX IF NOT EXISTS admin_audit_log_admin_idx ON admin_audit_log (admin_chat_id, created_at);
+CREATE INDEX IF NOT EXISTS admin_audit_log_action_idx ON admin_audit_log (action, created_at);
+
+-- Журнал только дополняется: изменить или удалить запись нельзя
+CREATE OR REPLACE FUNCTION admin_audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'admin_audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS admin_audit_log_append_only ON admin_audit_log;
+CREATE TRIGGER admin_audit_log_append_only
+    BEFORE UPDATE OR DELETE ON admin_audit_log
+    FOR EACH ROW EXECUTE FUNCTION admin_audit_log_append_only();
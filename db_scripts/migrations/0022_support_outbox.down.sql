@@ -0,0 +1,3 @@
+DELETE FROM notification_outbox WHERE conversation_id IS NOT NULL;
+ALTER TABLE notification_outbox DROP COLUMN IF EXISTS support_message_id;
+ALTER TABLE notification_outbox DROP COLUMN IF EXISTS conversation_id;
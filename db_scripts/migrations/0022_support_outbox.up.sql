@@ -0,0 +1,6 @@
+-- Ответы админов и уведомления о закрытии обращения доставляются через outbox
+-- с повторами, как и решения по заявкам
+ALTER TABLE notification_outbox
+    ADD COLUMN IF NOT EXISTS conversation_id INT REFERENCES support_conversations(id) ON DELETE CASCADE;
+ALTER TABLE notification_outbox
+    ADD COLUMN IF NOT EXISTS support_message_id INT REFERENCES support_messages(id) ON DELETE CASCADE;
@@ -0,0 +1,56 @@
+// Package access описывает роли админов и права, которые они дают
+package access
+
+type Role string
+
+const (
+	Owner          Role = "owner"
+	Moderator      Role = "moderator"
+	Support        Role = "support"
+	PartnerManager Role = "partner_manager"
+)
+
+// Roles — все роли в порядке показа в боте
+var Roles = []Role{Owner, Moderator, Support, PartnerManager}
+
+type Permission string
+
+const (
+	// Проверка заявок, очередь, отложенные и недоставленные уведомления о решениях
+	ReviewRequests Permission = "review_requests"
+	// Шаблоны причин отклонения и доработки
+	ManageReasons  Permission = "manage_reasons"
+	AnswerSupport  Permission = "answer_support"
+	ManagePartners Permission = "manage_partners"
+	ManageAdmins   Permission = "manage_admins"
+	ViewAudit      Permission = "view_audit"
+)
+
+// Владелец может всё, поэтому в таблице не перечисляется
+var permissions = map[Role][]Permission{
+	Moderator:      {ReviewRequests, ManageReasons},
+	Support:        {AnswerSupport},
+	PartnerManager: {ManagePartners},
+}
+
+func (r Role) Valid() bool {
+	switch r {
+	case Owner, Moderator, Support, PartnerManager:
+		return true
+	}
+	return false
+}
+
+func (r Role) Can(p Permission) bool {
+	if r == Owner {
+		return true
+	}
+
+	for _, allowed := range permissions[r] {
+		if allowed == p {
+			return true
+		}
+	}
+
+	return false
+}
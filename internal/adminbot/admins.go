@@ -0,0 +1,488 @@
+package adminbot
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/gratefultolord/ac_signup_bot/internal/access"
+	"github.com/gratefultolord/ac_signup_bot/internal/adminnotify"
+	"github.com/gratefultolord/ac_signup_bot/internal/db"
+)
+
+const (
+	adminsCallbackPrefix = "admins"
+
+	// 128 бит случайности; в base64 токен укладывается в лимит параметра start
+	inviteTokenBytes = 16
+)
+
+var roleTitles = map[access.Role]string{
+	access.Owner:          "Владелец",
+	access.Moderator:      "Модератор",
+	access.Support:        "Поддержка",
+	access.PartnerManager: "Менеджер партнёров",
+}
+
+// Кнопки главного меню и права, без которых они не показываются.
+// Пустое право — раздел доступен всем
+var mainMenuButtons = []struct {
+	title      string
+	permission access.Permission
+}{
+	{"Проверить заявки", access.ReviewRequests},
+	{"Очередь заявок", access.ReviewRequests},
+	{"Сообщения пользователей", access.AnswerSupport},
+	{"Недоставленные", access.ReviewRequests},
+	{"Партнёры", access.ManagePartners},
+	{"Уведомления", ""},
+	{"Шаблоны причин", access.ManageReasons},
+	{"Админы", access.ManageAdmins},
+	{"Журнал действий", access.ViewAudit},
+}
+
+// Шаги пошагового ввода и права, нужные чтобы его завершить
+var stepPermissions = map[string]access.Permission{
+	StateEnteringRejectReason:   access.ReviewRequests,
+	StateEnteringRevisionReason: access.ReviewRequests,
+	StateEnteringRequestNote:    access.ReviewRequests,
+	StateEnteringHoldNote:       access.ReviewRequests,
+	StateEnteringHoldReminder:   access.ReviewRequests,
+	StateEnteringReasonTemplate: access.ManageReasons,
+	StateEnteringCategoryTitle:  access.ManagePartners,
+	StateEnteringPartnerField:   access.ManagePartners,
+	StateEnteringSupportReply:   access.AnswerSupport,
+	StateEnteringAuditPeriod:    access.ViewAudit,
+}
+
+func roleTitle(role access.Role) string {
+	if title, ok := roleTitles[role]; ok {
+		return title
+	}
+	return string(role)
+}
+
+func menuPermission(text string) (access.Permission, bool) {
+	for _, button := range mainMenuButtons {
+		if button.title == text {
+			return button.permission, button.permission != ""
+		}
+	}
+
+	// Номер заявки вида #123 открывает её
+	if _, ok := parseRequestNumber(text); ok {
+		return access.ReviewRequests, true
+	}
+
+	return "", false
+}
+
+// Право, нужное для нажатия inline-кнопки; false, если кнопка доступна всем
+func callbackPermission(data string) (access.Permission, bool) {
+	switch {
+	case hasCallbackPrefix(data, partnersCallbackPrefix):
+		return access.ManagePartners, true
+	case hasCallbackPrefix(data, supportCallbackPrefix):
+		return access.AnswerSupport, true
+	case hasCallbackPrefix(data, outboxCallbackPrefix),
+		hasCallbackPrefix(data, queueCallbackPrefix),
+		hasCallbackPrefix(data, reviewCallbackPrefix),
+		strings.HasPrefix(data, reasonsCallbackPrefix+":pick:"):
+		return access.ReviewRequests, true
+	case hasCallbackPrefix(data, reasonsCallbackPrefix):
+		return access.ManageReasons, true
+	case hasCallbackPrefix(data, adminsCallbackPrefix):
+		return access.ManageAdmins, true
+	case hasCallbackPrefix(data, auditCallbackPrefix):
+		return access.ViewAudit, true
+	case data == adminnotify.OpenConversations,
+		strings.HasPrefix(data, adminnotify.CallbackPrefix+":conversation:"):
+		return access.AnswerSupport, true
+	case hasCallbackPrefix(data, adminnotify.CallbackPrefix):
+		return access.ReviewRequests, true
+	}
+
+	return "", false
+}
+
+// Админ, от которого пришёл апдейт; false, если доступа к боту у него нет
+func (b *BotService) currentAdmin(chatID int64) (*db.Admin, bool) {
+	admin, err := b.adminRepo.GetByChatID(chatID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false
+	}
+	if err != nil {
+		log.Printf("Error loading admin %d: %v\n", chatID, err)
+		return nil, false
+	}
+
+	return admin, true
+}
+
+// Главное меню с разделами, доступными роли админа
+func (b *BotService) mainMenu(chatID int64) tgbotapi.ReplyKeyboardMarkup {
+	role := access.Role("")
+	if admin, ok := b.currentAdmin(chatID); ok {
+		role = admin.Role
+	}
+
+	return AdminMainMenu(role)
+}
+
+func (b *BotService) denyAccess(chatID int64) {
+	b.adminStates[chatID] = &AdminState{Step: StateMainMenu}
+	b.restoreMainMenu(chatID, "Недостаточно прав")
+}
+
+func (b *BotService) handleAdmins(chatID int64) {
+	b.showAdmins(chatID, nil)
+}
+
+// Обработка нажатий в разделе «Админы»: admins — список, admins:a:<id> — админ,
+// admins:role:<id>:<role>, admins:del:<id>, admins:delok:<id>,
+// admins:new — выбор роли приглашения, admins:invite:<role>, admins:revoke:<invite_id>
+func (b *BotService) handleAdminsCallback(query *tgbotapi.CallbackQuery) {
+	if query.Message == nil {
+		b.botAPI.Request(tgbotapi.NewCallback(query.ID, ""))
+		return
+	}
+
+	chatID := query.Message.Chat.ID
+
+	parts := strings.Split(query.Data, ":")
+	if len(parts) == 1 {
+		b.botAPI.Request(tgbotapi.NewCallback(query.ID, ""))
+		b.showAdmins(chatID, query.Message)
+		return
+	}
+
+	if parts[1] == "new" {
+		b.botAPI.Request(tgbotapi.NewCallback(query.ID, ""))
+		b.showInviteRoles(chatID, query.Message)
+		return
+	}
+
+	if parts[1] == "invite" && len(parts) == 3 {
+		b.createInvite(query, access.Role(parts[2]))
+		return
+	}
+
+	if len(parts) < 3 {
+		b.botAPI.Request(tgbotapi.NewCallback(query.ID, ""))
+		log.Printf("bad admins callback %q", query.Data)
+		return
+	}
+
+	id, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		b.botAPI.Request(tgbotapi.NewCallback(query.ID, ""))
+		log.Printf("bad admins callback %q", query.Data)
+		return
+	}
+
+	switch {
+	case parts[1] == "a" && len(parts) == 3:
+		b.botAPI.Request(tgbotapi.NewCallback(query.ID, ""))
+		b.showAdmin(chatID, query.Message, id)
+
+	case parts[1] == "role" && len(parts) == 4:
+		target, err := b.adminRepo.GetByID(id)
+		if err != nil {
+			b.answerAdminsError(query, err)
+			return
+		}
+
+		role := access.Role(parts[3])
+		if err := b.adminRepo.SetRole(id, role); err != nil {
+			b.answerAdminsError(query, err)
+			return
+		}
+
+		b.audit(chatID, db.AuditAdminRole, auditTargetAdmin, target.ChatID, fmt.Sprintf("%s → %s", target.Role, role))
+
+		b.botAPI.Request(tgbotapi.NewCallback(query.ID, "Роль изменена: "+roleTitle(role)))
+		b.showAdmin(chatID, query.Message, id)
+
+	case parts[1] == "del" && len(parts) == 3:
+		b.botAPI.Request(tgbotapi.NewCallback(query.ID, ""))
+
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("Да, удалить", fmt.Sprintf("%s:delok:%d", adminsCallbackPrefix, id)),
+				tgbotapi.NewInlineKeyboardButtonData("Отмена", fmt.Sprintf("%s:a:%d", adminsCallbackPrefix, id)),
+			),
+		)
+		b.showInlineView(chatID, query.Message, "Удалить админа? Закреплённые за ним заявки вернутся в очередь", keyboard)
+
+	case parts[1] == "delok" && len(parts) == 3:
+		if admin, ok := b.currentAdmin(chatID); ok && admin.ID == id {
+			b.botAPI.Request(tgbotapi.NewCallbackWithAlert(query.ID, "Нельзя удалить самого себя"))
+			return
+		}
+
+		target, err := b.adminRepo.GetByID(id)
+		if err != nil {
+			b.answerAdminsError(query, err)
+			return
+		}
+
+		if err := b.adminRepo.Delete(id); err != nil {
+			b.answerAdminsError(query, err)
+			return
+		}
+
+		b.audit(chatID, db.AuditAdminRemove, auditTargetAdmin, target.ChatID, string(target.Role))
+
+		b.botAPI.Request(tgbotapi.NewCallback(query.ID, "Админ удалён"))
+		b.showAdmins(chatID, query.Message)
+
+	case parts[1] == "revoke" && len(parts) == 3:
+		if err := b.adminRepo.RevokeInvite(id); err != nil {
+			b.answerAdminsError(query, err)
+			return
+		}
+
+		b.audit(chatID, db.AuditInviteRevoke, auditTargetInvite, id, "")
+
+		b.botAPI.Request(tgbotapi.NewCallback(query.ID, "Приглашение отозвано"))
+		b.showAdmins(chatID, query.Message)
+
+	default:
+		b.botAPI.Request(tgbotapi.NewCallback(query.ID, ""))
+		log.Printf("bad admins callback %q", query.Data)
+	}
+}
+
+func (b *BotService) answerAdminsError(query *tgbotapi.CallbackQuery, err error) {
+	text := "Не удалось сохранить изменения"
+
+	switch {
+	case errors.Is(err, db.ErrLastOwner):
+		text = "Должен остаться хотя бы один владелец"
+	case errors.Is(err, db.ErrValidation):
+		log.Printf("bad admins callback %q: %v", query.Data, err)
+	default:
+		log.Printf("Error managing admins: %v\n", err)
+	}
+
+	b.botAPI.Request(tgbotapi.NewCallbackWithAlert(query.ID, text))
+}
+
+func (b *BotService) showAdmins(chatID int64, current *tgbotapi.Message) {
+	admins, err := b.adminRepo.GetAll()
+	if err != nil {
+		log.Printf("Error loading admins: %v\n", err)
+		msg := tgbotapi.NewMessage(chatID, "Ошибка при загрузке админов")
+		b.botAPI.Send(msg)
+		return
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Админы: %d\n", len(admins))
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, a := range admins {
+		you := ""
+		if a.ChatID == chatID {
+			you = " (вы)"
+		}
+
+		fmt.Fprintf(&sb, "\n%d — %s%s", a.ChatID, roleTitle(a.Role), you)
+
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("%d · %s", a.ChatID, roleTitle(a.Role)),
+				fmt.Sprintf("%s:a:%d", adminsCallbackPrefix, a.ID),
+			),
+		))
+	}
+
+	invites, err := b.adminRepo.ListInvites()
+	if err != nil {
+		log.Printf("Error loading admin invites: %v\n", err)
+	}
+
+	if len(invites) > 0 {
+		sb.WriteString("\n\nДействующие приглашения:")
+	}
+
+	for _, invite := range invites {
+		expires := invite.ExpiresAt.Format("02.01.2006 15:04")
+		fmt.Fprintf(&sb, "\n%s — до %s", roleTitle(invite.Role), expires)
+
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("Отозвать: %s до %s", roleTitle(invite.Role), expires),
+				fmt.Sprintf("%s:revoke:%d", adminsCallbackPrefix, invite.ID),
+			),
+		))
+	}
+
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("+ Пригласить админа", adminsCallbackPrefix+":new"),
+	))
+
+	b.showInlineView(chatID, current, sb.String(), tgbotapi.NewInlineKeyboardMarkup(rows...))
+}
+
+func (b *BotService) showAdmin(chatID int64, current *tgbotapi.Message, adminID int64) {
+	admin, err := b.adminRepo.GetByID(adminID)
+	if errors.Is(err, sql.ErrNoRows) {
+		b.showAdmins(chatID, current)
+		return
+	}
+	if err != nil {
+		log.Printf("Error loading admin %d: %v\n", adminID, err)
+		msg := tgbotapi.NewMessage(chatID, "Ошибка при загрузке админа")
+		b.botAPI.Send(msg)
+		return
+	}
+
+	text := fmt.Sprintf("Админ %d\nРоль: <b>%s</b>\nДобавлен: %s",
+		admin.ChatID, html.EscapeString(roleTitle(admin.Role)), admin.CreatedAt.Format("02.01.2006"))
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, role := range access.Roles {
+		if role == admin.Role {
+			continue
+		}
+
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				"Сделать: "+roleTitle(role),
+				fmt.Sprintf("%s:role:%d:%s", adminsCallbackPrefix, admin.ID, role),
+			),
+		))
+	}
+
+	if admin.ChatID != chatID {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Удалить", fmt.Sprintf("%s:del:%d", adminsCallbackPrefix, admin.ID)),
+		))
+	}
+
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("« Все админы", adminsCallbackPrefix),
+	))
+
+	b.showInlineView(chatID, current, text, tgbotapi.NewInlineKeyboardMarkup(rows...))
+}
+
+func (b *BotService) showInviteRoles(chatID int64, current *tgbotapi.Message) {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, role := range access.Roles {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(roleTitle(role), fmt.Sprintf("%s:invite:%s", adminsCallbackPrefix, role)),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("« Все админы", adminsCallbackPrefix),
+	))
+
+	b.showInlineView(chatID, current, "Выберите роль нового админа. Роль можно будет изменить позже", tgbotapi.NewInlineKeyboardMarkup(rows...))
+}
+
+// Одноразовая ссылка вида t.me/<бот>?start=<токен>. Сам токен нигде не хранится,
+// поэтому потерянную ссылку нельзя показать снова — только отозвать и создать новую
+func (b *BotService) createInvite(query *tgbotapi.CallbackQuery, role access.Role) {
+	chatID := query.Message.Chat.ID
+
+	admin, ok := b.currentAdmin(chatID)
+	if !ok {
+		b.botAPI.Request(tgbotapi.NewCallback(query.ID, "Доступ запрещен"))
+		return
+	}
+
+	token, err := newInviteToken()
+	if err != nil {
+		b.answerAdminsError(query, err)
+		return
+	}
+
+	invite, err := b.adminRepo.CreateInvite(token, role, admin.ID, b.inviteTTL)
+	if err != nil {
+		b.answerAdminsError(query, err)
+		return
+	}
+
+	b.audit(chatID, db.AuditAdminInvite, auditTargetInvite, invite.ID, string(role))
+
+	b.botAPI.Request(tgbotapi.NewCallback(query.ID, ""))
+
+	text := fmt.Sprintf(
+		"Приглашение для роли «%s». Ссылка одноразовая и действует до %s. Перешлите её новому админу:\n\nhttps://t.me/%s?start=%s",
+		roleTitle(role), invite.ExpiresAt.Format("02.01.2006 15:04"), b.botAPI.Self.UserName, token,
+	)
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Отозвать", fmt.Sprintf("%s:revoke:%d", adminsCallbackPrefix, invite.ID)),
+			tgbotapi.NewInlineKeyboardButtonData("« Все админы", adminsCallbackPrefix),
+		),
+	)
+
+	b.showInlineView(chatID, query.Message, text, keyboard)
+}
+
+func newInviteToken() (string, error) {
+	raw := make([]byte, inviteTokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// /start <токен> из ссылки-приглашения: добавить пользователя в админы
+// и сообщить пригласившему
+func (b *BotService) handleInvite(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+
+	invite, err := b.adminRepo.AcceptInvite(message.CommandArguments(), chatID)
+	switch {
+	case errors.Is(err, db.ErrAlreadyAdmin):
+		b.handleMainMenu(chatID)
+		return
+	case errors.Is(err, db.ErrInviteInvalid):
+		msg := tgbotapi.NewMessage(chatID, "Ссылка-приглашение недействительна или истекла. Попросите владельца прислать новую")
+		b.botAPI.Send(msg)
+		return
+	case err != nil:
+		log.Printf("Error accepting admin invite: %v\n", err)
+		msg := tgbotapi.NewMessage(chatID, "Не удалось принять приглашение. Попробуйте позже")
+		b.botAPI.Send(msg)
+		return
+	}
+
+	b.audit(chatID, db.AuditAdminJoin, auditTargetInvite, invite.ID, string(invite.Role))
+
+	b.adminStates[chatID] = &AdminState{Step: StateMainMenu}
+	b.restoreMainMenu(chatID, fmt.Sprintf("Добро пожаловать! Ваша роль: %s", roleTitle(invite.Role)))
+
+	inviter, err := b.adminRepo.GetByID(invite.InvitedBy)
+	if err != nil {
+		log.Printf("Error loading admin %d: %v\n", invite.InvitedBy, err)
+		return
+	}
+
+	name := strings.TrimSpace(message.From.FirstName + " " + message.From.LastName)
+	if message.From.UserName != "" {
+		name += " @" + message.From.UserName
+	}
+
+	msg := tgbotapi.NewMessage(inviter.ChatID, fmt.Sprintf(
+		"✅ По вашему приглашению добавлен админ %s (chat_id %d), роль: %s",
+		name, chatID, roleTitle(invite.Role),
+	))
+	if _, err := b.botAPI.Send(msg); err != nil {
+		log.Printf("Error notifying inviter %d: %v\n", inviter.ChatID, err)
+	}
+}
@@ -0,0 +1,587 @@
+package adminbot
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"html"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/gratefultolord/ac_signup_bot/internal/db"
+)
+
+const (
+	auditCallbackPrefix = "audit"
+
+	auditPageSize     = 15
+	auditDetailsLimit = 100
+
+	// Больше строк в одну выгрузку не попадает, об этом пишется в подписи к файлу
+	auditExportLimit = 10000
+
+	// Свой период в данных кнопок: 20261001-20261015
+	auditRangeLayout = "20060102"
+)
+
+// Объекты действий: в журнале хранится тип и номер
+const (
+	auditTargetRequest      = "request"
+	auditTargetAdmin        = "admin"
+	auditTargetInvite       = "invite"
+	auditTargetReason       = "reason"
+	auditTargetCategory     = "category"
+	auditTargetPartner      = "partner"
+	auditTargetConversation = "conversation"
+	auditTargetNotification = "notification"
+)
+
+// Действия в порядке показа в фильтре
+var auditActions = []string{
+	db.AuditRequestApprove,
+	db.AuditRequestReject,
+	db.AuditRequestRevise,
+	db.AuditRequestHold,
+	db.AuditRequestResume,
+	db.AuditRequestNote,
+	db.AuditDocumentView,
+	db.AuditAdminInvite,
+	db.AuditInviteRevoke,
+	db.AuditAdminJoin,
+	db.AuditAdminRole,
+	db.AuditAdminRemove,
+	db.AuditReasonCreate,
+	db.AuditReasonUpdate,
+	db.AuditReasonDelete,
+	db.AuditCatalogChange,
+	db.AuditSupportReply,
+	db.AuditSupportClose,
+	db.AuditOutboxRetry,
+	db.AuditExport,
+}
+
+var auditActionTitles = map[string]string{
+	db.AuditRequestApprove: "Одобрение заявки",
+	db.AuditRequestReject:  "Отклонение заявки",
+	db.AuditRequestRevise:  "Отправка на доработку",
+	db.AuditRequestHold:    "Заявка отложена",
+	db.AuditRequestResume:  "Заявка возвращена в работу",
+	db.AuditRequestNote:    "Заметка к заявке",
+	db.AuditDocumentView:   "Просмотр документа",
+	db.AuditAdminInvite:    "Приглашение админа",
+	db.AuditInviteRevoke:   "Отзыв приглашения",
+	db.AuditAdminJoin:      "Вход по приглашению",
+	db.AuditAdminRole:      "Смена роли",
+	db.AuditAdminRemove:    "Удаление админа",
+	db.AuditReasonCreate:   "Новый шаблон причины",
+	db.AuditReasonUpdate:   "Изменение шаблона причины",
+	db.AuditReasonDelete:   "Удаление шаблона причины",
+	db.AuditCatalogChange:  "Изменение каталога",
+	db.AuditSupportReply:   "Ответ пользователю",
+	db.AuditSupportClose:   "Закрытие обращения",
+	db.AuditOutboxRetry:    "Повтор уведомления",
+	db.AuditExport:         "Выгрузка журнала",
+}
+
+var auditTargetTitles = map[string]string{
+	auditTargetRequest:      "заявка #%d",
+	auditTargetAdmin:        "админ %d",
+	auditTargetInvite:       "приглашение #%d",
+	auditTargetReason:       "шаблон #%d",
+	auditTargetCategory:     "категория #%d",
+	auditTargetPartner:      "партнёр #%d",
+	auditTargetConversation: "обращение #%d",
+	auditTargetNotification: "уведомление #%d",
+}
+
+// Записать действие админа в журнал. Ошибка записи не отменяет уже сделанное действие
+func (b *BotService) audit(chatID int64, action, targetType string, targetID int64, details string) {
+	entry := db.AuditEntry{AdminChatID: chatID, Action: action}
+
+	if targetType != "" {
+		entry.TargetType = &targetType
+		entry.TargetID = &targetID
+	}
+
+	if details != "" {
+		entry.Details = &details
+	}
+
+	if err := b.auditRepo.Record(entry); err != nil {
+		log.Printf("Error writing audit log: %v\n", err)
+	}
+}
+
+// auditView — фильтры журнала, целиком хранятся в данных кнопок:
+// audit:<вид>:<admin_chat_id>:<action>:<period>:<page>. Вид: l — список,
+// w — выбор админа, a — выбор действия, p — ввод периода, c — выгрузка CSV
+type auditView struct {
+	admin  int64
+	action string
+	period string
+	page   int
+}
+
+func (v auditView) data(kind string) string {
+	return fmt.Sprintf("%s:%s:%d:%s:%s:%d", auditCallbackPrefix, kind, v.admin, v.action, v.period, v.page)
+}
+
+func (v auditView) filter() db.AuditFilter {
+	filter := db.AuditFilter{AdminChatID: v.admin}
+
+	if v.action != queueAll {
+		filter.Action = v.action
+	}
+
+	if days, ok := queuePeriodDays[v.period]; ok {
+		filter.Since = time.Now().AddDate(0, 0, -days)
+	}
+
+	if since, until, ok := parseAuditRange(v.period); ok {
+		filter.Since, filter.Until = since, until
+	}
+
+	return filter
+}
+
+func (v auditView) periodTitle() string {
+	if title, ok := queuePeriodTitles[v.period]; ok {
+		return title
+	}
+
+	since, until, _ := parseAuditRange(v.period)
+	return since.Format("02.01.2006") + "–" + until.AddDate(0, 0, -1).Format("02.01.2006")
+}
+
+func parseAuditView(parts []string) (auditView, bool) {
+	if len(parts) != 6 {
+		return auditView{}, false
+	}
+
+	admin, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return auditView{}, false
+	}
+
+	page, err := strconv.Atoi(parts[5])
+	if err != nil || page < 0 {
+		return auditView{}, false
+	}
+
+	view := auditView{admin: admin, action: parts[3], period: parts[4], page: page}
+
+	if view.action != queueAll && auditActionTitles[view.action] == "" {
+		return auditView{}, false
+	}
+
+	if _, _, ok := parseAuditRange(view.period); !ok && queuePeriodTitles[view.period] == "" {
+		return auditView{}, false
+	}
+
+	return view, true
+}
+
+// Свой период из данных кнопки; until — начало дня после последнего дня периода
+func parseAuditRange(period string) (time.Time, time.Time, bool) {
+	from, to, ok := strings.Cut(period, "-")
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+
+	since, err := time.ParseInLocation(auditRangeLayout, from, time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+
+	last, err := time.ParseInLocation(auditRangeLayout, to, time.Local)
+	if err != nil || last.Before(since) {
+		return time.Time{}, time.Time{}, false
+	}
+
+	return since, last.AddDate(0, 0, 1), true
+}
+
+func auditActionTitle(action string) string {
+	if title, ok := auditActionTitles[action]; ok {
+		return title
+	}
+	return action
+}
+
+func auditTarget(entry db.AuditEntry) string {
+	if entry.TargetType == nil || entry.TargetID == nil {
+		return ""
+	}
+
+	if format, ok := auditTargetTitles[*entry.TargetType]; ok {
+		return fmt.Sprintf(format, *entry.TargetID)
+	}
+
+	return fmt.Sprintf("%s #%d", *entry.TargetType, *entry.TargetID)
+}
+
+func (b *BotService) handleAudit(chatID int64) {
+	b.showAudit(chatID, nil, auditView{action: queueAll, period: "7d"})
+}
+
+func (b *BotService) handleAuditCallback(query *tgbotapi.CallbackQuery) {
+	b.botAPI.Request(tgbotapi.NewCallback(query.ID, ""))
+
+	if query.Message == nil {
+		return
+	}
+
+	chatID := query.Message.Chat.ID
+
+	// Нажатие кнопки прерывает ввод периода
+	if b.adminStates[chatID].Step == StateEnteringAuditPeriod {
+		b.adminStates[chatID] = &AdminState{Step: StateMainMenu}
+	}
+
+	parts := strings.Split(query.Data, ":")
+
+	view, ok := parseAuditView(parts)
+	if !ok {
+		log.Printf("bad audit callback %q", query.Data)
+		return
+	}
+
+	switch parts[1] {
+	case "l":
+		b.showAudit(chatID, query.Message, view)
+
+	case "w":
+		b.showAuditAdmins(chatID, query.Message, view)
+
+	case "a":
+		b.showAuditActions(chatID, query.Message, view)
+
+	case "p":
+		b.adminStates[chatID] = &AdminState{Step: StateEnteringAuditPeriod, AuditView: view.data("l")}
+
+		msg := tgbotapi.NewMessage(chatID, "Введите период в формате ДД.ММ.ГГГГ-ДД.ММ.ГГГГ или один день ДД.ММ.ГГГГ")
+		msg.ReplyMarkup = CancelMenu()
+		b.botAPI.Send(msg)
+
+	case "c":
+		b.exportAudit(chatID, view)
+
+	default:
+		log.Printf("bad audit callback %q", query.Data)
+	}
+}
+
+func (b *BotService) showAudit(chatID int64, current *tgbotapi.Message, view auditView) {
+	filter := view.filter()
+
+	total, err := b.auditRepo.Count(filter)
+	if err != nil {
+		log.Printf("Error counting audit log: %v\n", err)
+		msg := tgbotapi.NewMessage(chatID, "Ошибка при загрузке журнала")
+		b.botAPI.Send(msg)
+		return
+	}
+
+	if pages := (total + auditPageSize - 1) / auditPageSize; view.page >= pages && pages > 0 {
+		view.page = pages - 1
+	}
+
+	entries, err := b.auditRepo.List(filter, db.Page{Limit: auditPageSize, Offset: view.page * auditPageSize})
+	if err != nil {
+		log.Printf("Error loading audit log: %v\n", err)
+		msg := tgbotapi.NewMessage(chatID, "Ошибка при загрузке журнала")
+		b.botAPI.Send(msg)
+		return
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "<b>Журнал действий</b>: %d", total)
+	if total > auditPageSize {
+		fmt.Fprintf(&sb, ", страница %d из %d", view.page+1, (total+auditPageSize-1)/auditPageSize)
+	}
+	sb.WriteString("\n")
+
+	if len(entries) == 0 {
+		sb.WriteString("\nЗаписей нет")
+	}
+
+	for _, e := range entries {
+		fmt.Fprintf(&sb, "\n%s · %d · %s", e.CreatedAt.Format("02.01.2006 15:04"), e.AdminChatID, auditActionTitle(e.Action))
+
+		if target := auditTarget(e); target != "" {
+			sb.WriteString(" · " + target)
+		}
+
+		if e.Details != nil {
+			fmt.Fprintf(&sb, "\n    %s", html.EscapeString(truncateText(*e.Details, auditDetailsLimit)))
+		}
+	}
+
+	who := "все"
+	if view.admin != 0 {
+		who = strconv.FormatInt(view.admin, 10)
+	}
+
+	what := "все"
+	if view.action != queueAll {
+		what = auditActionTitle(view.action)
+	}
+
+	first := view
+	first.page = 0
+
+	nextPeriod := first
+	nextPeriod.period = nextOption(queuePeriods, view.period)
+
+	rows := [][]tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Кто: "+who, first.data("w")),
+			tgbotapi.NewInlineKeyboardButtonData("Что: "+what, first.data("a")),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Когда: "+view.periodTitle(), nextPeriod.data("l")),
+			tgbotapi.NewInlineKeyboardButtonData("Свой период", first.data("p")),
+		),
+	}
+
+	var nav []tgbotapi.InlineKeyboardButton
+	if view.page > 0 {
+		prev := view
+		prev.page--
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData("‹ Назад", prev.data("l")))
+	}
+	if (view.page+1)*auditPageSize < total {
+		next := view
+		next.page++
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData("Вперёд ›", next.data("l")))
+	}
+	if len(nav) > 0 {
+		rows = append(rows, nav)
+	}
+
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("Выгрузить CSV", first.data("c")),
+	))
+
+	b.showInlineView(chatID, current, sb.String(), tgbotapi.NewInlineKeyboardMarkup(rows...))
+}
+
+// Выбор админа: действующие и те, кто уже удалён, но есть в журнале
+func (b *BotService) showAuditAdmins(chatID int64, current *tgbotapi.Message, view auditView) {
+	chatIDs, err := b.auditRepo.ListAdmins()
+	if err != nil {
+		log.Printf("Error loading audit log admins: %v\n", err)
+		msg := tgbotapi.NewMessage(chatID, "Ошибка при загрузке журнала")
+		b.botAPI.Send(msg)
+		return
+	}
+
+	admins, err := b.adminRepo.GetAll()
+	if err != nil {
+		log.Printf("Error loading admins: %v\n", err)
+	}
+
+	roles := make(map[int64]string, len(admins))
+	for _, a := range admins {
+		roles[a.ChatID] = roleTitle(a.Role)
+		if !containsChatID(chatIDs, a.ChatID) {
+			chatIDs = append(chatIDs, a.ChatID)
+		}
+	}
+
+	all := view
+	all.admin = 0
+
+	rows := [][]tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("Все админы", all.data("l"))),
+	}
+
+	for _, id := range chatIDs {
+		role, ok := roles[id]
+		if !ok {
+			role = "удалён"
+		}
+
+		target := view
+		target.admin = id
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%d · %s", id, role), target.data("l")),
+		))
+	}
+
+	b.showInlineView(chatID, current, "Чьи действия показать?", tgbotapi.NewInlineKeyboardMarkup(rows...))
+}
+
+func containsChatID(chatIDs []int64, chatID int64) bool {
+	for _, id := range chatIDs {
+		if id == chatID {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *BotService) showAuditActions(chatID int64, current *tgbotapi.Message, view auditView) {
+	all := view
+	all.action = queueAll
+
+	rows := [][]tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("Все действия", all.data("l"))),
+	}
+
+	// Действия по два в ряд, чтобы список помещался на экран
+	var row []tgbotapi.InlineKeyboardButton
+	for _, action := range auditActions {
+		target := view
+		target.action = action
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(auditActionTitle(action), target.data("l")))
+
+		if len(row) == 2 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+
+	b.showInlineView(chatID, current, "Какие действия показать?", tgbotapi.NewInlineKeyboardMarkup(rows...))
+}
+
+func (b *BotService) handleAuditPeriod(chatID int64, text string) {
+	state := b.adminStates[chatID]
+
+	view, ok := parseAuditView(strings.Split(state.AuditView, ":"))
+	if !ok {
+		view = auditView{action: queueAll, period: queueAll}
+	}
+
+	if text == "Отмена" {
+		b.adminStates[chatID] = &AdminState{Step: StateMainMenu}
+		b.restoreMainMenu(chatID, "Отменено")
+		b.showAudit(chatID, nil, view)
+		return
+	}
+
+	period, ok := parseAuditPeriodInput(text)
+	if !ok {
+		msg := tgbotapi.NewMessage(chatID, "Не удалось разобрать период. Пример: 01.10.2026-15.10.2026")
+		msg.ReplyMarkup = CancelMenu()
+		b.botAPI.Send(msg)
+		return
+	}
+
+	view.period = period
+	view.page = 0
+
+	b.adminStates[chatID] = &AdminState{Step: StateMainMenu}
+	b.restoreMainMenu(chatID, "Период: "+view.periodTitle())
+	b.showAudit(chatID, nil, view)
+}
+
+// Текст, введённый админами, не должен превращаться в формулу при открытии в Excel
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// Период из текста «ДД.ММ.ГГГГ-ДД.ММ.ГГГГ» или «ДД.ММ.ГГГГ» в формате данных кнопок
+func parseAuditPeriodInput(text string) (string, bool) {
+	from, to, found := strings.Cut(strings.TrimSpace(text), "-")
+	if !found {
+		to = from
+	}
+
+	since, err := time.ParseInLocation("02.01.2006", strings.TrimSpace(from), time.Local)
+	if err != nil {
+		return "", false
+	}
+
+	last, err := time.ParseInLocation("02.01.2006", strings.TrimSpace(to), time.Local)
+	if err != nil || last.Before(since) {
+		return "", false
+	}
+
+	return since.Format(auditRangeLayout) + "-" + last.Format(auditRangeLayout), true
+}
+
+func (b *BotService) exportAudit(chatID int64, view auditView) {
+	filter := view.filter()
+
+	entries, err := b.auditRepo.List(filter, db.Page{Limit: auditExportLimit})
+	if err != nil {
+		log.Printf("Error loading audit log: %v\n", err)
+		msg := tgbotapi.NewMessage(chatID, "Ошибка при выгрузке журнала")
+		b.botAPI.Send(msg)
+		return
+	}
+
+	var buf bytes.Buffer
+
+	// BOM, чтобы Excel открыл файл в UTF-8
+	buf.WriteString("\ufeff")
+
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"id", "created_at", "admin_chat_id", "action", "action_title", "target_type", "target_id", "details"})
+
+	for _, e := range entries {
+		var targetType, targetID, details string
+		if e.TargetType != nil {
+			targetType = *e.TargetType
+		}
+		if e.TargetID != nil {
+			targetID = strconv.FormatInt(*e.TargetID, 10)
+		}
+		if e.Details != nil {
+			details = *e.Details
+		}
+
+		w.Write([]string{
+			strconv.FormatInt(e.ID, 10),
+			e.CreatedAt.Format(time.RFC3339),
+			strconv.FormatInt(e.AdminChatID, 10),
+			e.Action,
+			auditActionTitle(e.Action),
+			targetType,
+			targetID,
+			csvSafe(details),
+		})
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		log.Printf("Error writing audit CSV: %v\n", err)
+		msg := tgbotapi.NewMessage(chatID, "Ошибка при выгрузке журнала")
+		b.botAPI.Send(msg)
+		return
+	}
+
+	description := fmt.Sprintf("кто: %d, что: %s, когда: %s", view.admin, view.action, view.periodTitle())
+	if view.admin == 0 {
+		description = fmt.Sprintf("кто: все, что: %s, когда: %s", view.action, view.periodTitle())
+	}
+
+	caption := fmt.Sprintf("Журнал действий: %d записей", len(entries))
+	if len(entries) == auditExportLimit {
+		caption += fmt.Sprintf(". Выгружены только последние %d, сузьте фильтр", auditExportLimit)
+	}
+
+	doc := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{
+		Name:  fmt.Sprintf("audit_%s.csv", time.Now().Format("20060102_1504")),
+		Bytes: buf.Bytes(),
+	})
+	doc.Caption = caption
+
+	if _, err := b.botAPI.Send(doc); err != nil {
+		log.Printf("Error sending audit CSV: %v\n", err)
+		return
+	}
+
+	b.audit(chatID, db.AuditExport, "", 0, description)
+}
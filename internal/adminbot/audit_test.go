@@ -0,0 +1,132 @@
+package adminbot
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseAuditRange(t *testing.T) {
+	tests := []struct {
+		period string
+		since  time.Time
+		until  time.Time
+		ok     bool
+	}{
+		{
+			period: "20260301-20260331",
+			since:  time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local),
+			until:  time.Date(2026, 4, 1, 0, 0, 0, 0, time.Local),
+			ok:     true,
+		},
+		{
+			period: "20261231-20261231",
+			since:  time.Date(2026, 12, 31, 0, 0, 0, 0, time.Local),
+			until:  time.Date(2027, 1, 1, 0, 0, 0, 0, time.Local),
+			ok:     true,
+		},
+		{period: "20260331-20260301"},
+		{period: "20260301"},
+		{period: "7d"},
+		{period: queueAll},
+		{period: "20260230-20260301"},
+		{period: "2026031-20260331"},
+		{period: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.period, func(t *testing.T) {
+			since, until, ok := parseAuditRange(tt.period)
+			if ok != tt.ok {
+				t.Fatalf("parseAuditRange(%q) ok = %v, want %v", tt.period, ok, tt.ok)
+			}
+
+			if ok && (!since.Equal(tt.since) || !until.Equal(tt.until)) {
+				t.Fatalf("parseAuditRange(%q) = %v, %v, want %v, %v", tt.period, since, until, tt.since, tt.until)
+			}
+		})
+	}
+}
+
+func TestParseAuditPeriodInput(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+		ok   bool
+	}{
+		{text: "01.03.2026-31.03.2026", want: "20260301-20260331", ok: true},
+		{text: " 01.03.2026 - 31.03.2026 ", want: "20260301-20260331", ok: true},
+		{text: "15.03.2026", want: "20260315-20260315", ok: true},
+		{text: "31.03.2026-01.03.2026"},
+		{text: "2026-03-01"},
+		{text: "01.03.2026-"},
+		{text: "31.02.2026"},
+		{text: "вчера"},
+		{text: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, ok := parseAuditPeriodInput(tt.text)
+			if ok != tt.ok || got != tt.want {
+				t.Fatalf("parseAuditPeriodInput(%q) = %q, %v, want %q, %v", tt.text, got, ok, tt.want, tt.ok)
+			}
+
+			if ok {
+				if _, _, rangeOK := parseAuditRange(got); !rangeOK {
+					t.Fatalf("parseAuditRange(%q) rejected parsed input", got)
+				}
+			}
+		})
+	}
+}
+
+func TestCSVSafe(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "", want: ""},
+		{value: "Документ нечитаем", want: "Документ нечитаем"},
+		{value: "=HYPERLINK(\"http://example.com\")", want: "'=HYPERLINK(\"http://example.com\")"},
+		{value: "+79991234567", want: "'+79991234567"},
+		{value: "-15%", want: "'-15%"},
+		{value: "@SUM(A1)", want: "'@SUM(A1)"},
+		{value: "a=b", want: "a=b"},
+	}
+
+	for _, tt := range tests {
+		if got := csvSafe(tt.value); got != tt.want {
+			t.Errorf("csvSafe(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestParseAuditView(t *testing.T) {
+	tests := []struct {
+		data string
+		want auditView
+		ok   bool
+	}{
+		{data: "audit:l:0:all:7d:0", want: auditView{action: queueAll, period: "7d"}, ok: true},
+		{data: "audit:c:42:request_reject:20260301-20260331:2", want: auditView{admin: 42, action: "request_reject", period: "20260301-20260331", page: 2}, ok: true},
+		{data: "audit:l:0:unknown_action:7d:0"},
+		{data: "audit:l:0:all:90d:0"},
+		{data: "audit:l:x:all:7d:0"},
+		{data: "audit:l:0:all:7d:-1"},
+		{data: "audit:l:0:all:7d"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.data, func(t *testing.T) {
+			got, ok := parseAuditView(strings.Split(tt.data, ":"))
+			if ok != tt.ok {
+				t.Fatalf("parseAuditView(%q) ok = %v, want %v", tt.data, ok, tt.ok)
+			}
+
+			if ok && got != tt.want {
+				t.Fatalf("parseAuditView(%q) = %+v, want %+v", tt.data, got, tt.want)
+			}
+		})
+	}
+}
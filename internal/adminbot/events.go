@@ -0,0 +1,52 @@
+package adminbot
+
+import (
+	"log"
+
+	"github.com/gratefultolord/ac_signup_bot/internal/db"
+	"github.com/gratefultolord/ac_signup_bot/internal/events"
+)
+
+func (b *BotService) publish(event events.Event) {
+	if err := b.publisher.Publish(event); err != nil {
+		log.Printf("Error publishing %s event: %v\n", event.Type, err)
+	}
+}
+
+// Реакция на события бота регистрации: новые и исправленные заявки, сообщения пользователей
+func (b *BotService) handleEvent(event events.Event) {
+	switch event.Type {
+	case events.RequestSubmitted:
+		req, err := b.registrationRepo.GetByID(event.RequestID)
+		if err != nil {
+			log.Printf("Error loading request %d: %v\n", event.RequestID, err)
+			return
+		}
+
+		if req.RevisionCount > 0 {
+			b.notifier.RequestResubmitted(req)
+		} else {
+			b.notifier.NewRequest(req)
+		}
+
+	case events.MessageSent:
+		message, err := b.supportRepo.GetMessage(event.MessageID)
+		if err != nil {
+			log.Printf("Error loading support message %d: %v\n", event.MessageID, err)
+			return
+		}
+
+		// Ответы админов публикует сам бот администратора
+		if message.Sender != db.SenderUser {
+			return
+		}
+
+		conversation, err := b.supportRepo.GetByID(message.ConversationID)
+		if err != nil {
+			log.Printf("Error loading conversation %d: %v\n", message.ConversationID, err)
+			return
+		}
+
+		b.notifier.NewMessage(conversation, message.Text)
+	}
+}
@@ -1,14 +1,15 @@
 package adminbot
 
 import (
-	"fmt"
 	"log"
-	"strconv"
+	"strings"
+	"time"
 
-	"github.com/AlekSi/pointer"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
+	"github.com/gratefultolord/ac_signup_bot/internal/adminnotify"
 	"github.com/gratefultolord/ac_signup_bot/internal/db"
+	"github.com/gratefultolord/ac_signup_bot/internal/events"
 	"github.com/gratefultolord/ac_signup_bot/internal/files"
 )
 
@@ -18,7 +19,18 @@ type BotService struct {
 	userRepo         *db.UserRepository
 	tokenRepo        *db.TokenRepository
 	adminRepo        *db.AdminRepository
+	categoryRepo     *db.CategoryRepository
+	partnerRepo      *db.PartnerRepository
+	supportRepo      *db.SupportRepository
+	outboxRepo       *db.OutboxRepository
+	reasonRepo       *db.ReasonTemplateRepository
+	auditRepo        *db.AuditRepository
 	fileService      *files.FileService
+	photoService     *files.FileService
+	publisher        *events.Publisher
+	notifier         *adminnotify.Notifier
+	claimTTL         time.Duration
+	inviteTTL        time.Duration
 	adminStates      map[int64]*AdminState
 }
 
@@ -28,7 +40,18 @@ func New(
 	userRepo *db.UserRepository,
 	tokenRepo *db.TokenRepository,
 	adminRepo *db.AdminRepository,
+	categoryRepo *db.CategoryRepository,
+	partnerRepo *db.PartnerRepository,
+	supportRepo *db.SupportRepository,
+	outboxRepo *db.OutboxRepository,
+	reasonRepo *db.ReasonTemplateRepository,
+	auditRepo *db.AuditRepository,
 	fileService *files.FileService,
+	photoService *files.FileService,
+	publisher *events.Publisher,
+	notifier *adminnotify.Notifier,
+	claimTTL time.Duration,
+	inviteTTL time.Duration,
 ) *BotService {
 	return &BotService{
 		botAPI:           botAPI,
@@ -36,311 +59,247 @@ func New(
 		userRepo:         userRepo,
 		tokenRepo:        tokenRepo,
 		adminRepo:        adminRepo,
+		categoryRepo:     categoryRepo,
+		partnerRepo:      partnerRepo,
+		supportRepo:      supportRepo,
+		outboxRepo:       outboxRepo,
+		reasonRepo:       reasonRepo,
+		auditRepo:        auditRepo,
 		fileService:      fileService,
+		photoService:     photoService,
+		publisher:        publisher,
+		notifier:         notifier,
+		claimTTL:         claimTTL,
+		inviteTTL:        inviteTTL,
 		adminStates:      make(map[int64]*AdminState),
 	}
 }
 
-func (b *BotService) Start(botToken string) {
+func (b *BotService) Start(incoming <-chan events.Event) {
 	u := tgbotapi.NewUpdate(0)
 	u.Timeout = 60
 	updates := b.botAPI.GetUpdatesChan(u)
 
-	for update := range updates {
-		if update.Message == nil {
-			continue
-		}
-
-		chatID := update.Message.Chat.ID
-		text := update.Message.Text
-
-		isAdmin, err := b.adminRepo.IsAdmin(chatID)
-		if err != nil || !isAdmin {
-			msg := tgbotapi.NewMessage(chatID, "Доступ запрещен")
-			b.botAPI.Send(msg)
-			continue
-		}
-
-		if _, exists := b.adminStates[chatID]; !exists {
-			b.adminStates[chatID] = &AdminState{Step: StateMainMenu}
-		}
-
-		state := b.adminStates[chatID]
-
-		if state.Step == StateMainMenu {
-			switch text {
-			case "/start", "Главное меню":
-				b.handleMainMenu(chatID)
-			case "Проверить заявки":
-				b.handleCheckRequests(chatID)
-			case "Сообщения пользователей":
-				b.handleMessages(chatID)
-			case "Добавить админа":
-				b.handleAddAdmin(chatID)
-			default:
-				b.handleMainMenu(chatID)
+	// События из бота регистрации обрабатываются в том же цикле, что и апдейты,
+	// поэтому adminStates не нужно защищать мьютексом
+	for {
+		select {
+		case update, ok := <-updates:
+			if !ok {
+				return
 			}
-			continue
-		}
-
-		switch state.Step {
-		case StateViewingRequest:
-			b.handleViewRequest(chatID, text, botToken)
-
-		case StateEnteringRejectReason:
-			b.handleRejectReason(chatID, text, botToken)
-
-		case StateEnteringRevisionReason:
-			b.handleRevisionReason(chatID, text, botToken)
+			b.handleUpdate(update)
 
-		case StateAddingAdmin:
-			b.handleAddingAdmin(chatID, text)
-
-		default:
-			log.Printf("Unknown state %s for chatID %d", state.Step, chatID)
-			b.handleMainMenu(chatID)
+		case event, ok := <-incoming:
+			if !ok {
+				incoming = nil
+				continue
+			}
+			b.handleEvent(event)
 		}
 	}
 }
 
-func (b *BotService) handleMainMenu(chatID int64) {
-	b.adminStates[chatID] = &AdminState{Step: StateMainMenu}
-
-	msg := tgbotapi.NewMessage(chatID, "Главное меню:")
-	msg.ReplyMarkup = AdminMainMenu()
-	b.botAPI.Send(msg)
-}
-
-func (b *BotService) handleCheckRequests(chatID int64) {
-	req, err := b.registrationRepo.GetNextPending()
-	if err != nil {
-		log.Printf("Error loading pending request: %v\n", err)
-		msg := tgbotapi.NewMessage(chatID, "Ошибка при получении заявок.")
-		b.botAPI.Send(msg)
+func (b *BotService) handleUpdate(update tgbotapi.Update) {
+	if update.CallbackQuery != nil {
+		b.handleCallback(update.CallbackQuery)
 		return
 	}
 
-	if req == nil {
-		msg := tgbotapi.NewMessage(chatID, "Нет новых заявок")
-		msg.ReplyMarkup = AdminMainMenu()
-		b.botAPI.Send(msg)
-		b.adminStates[chatID] = &AdminState{Step: StateMainMenu}
+	if update.Message == nil {
 		return
 	}
 
-	b.adminStates[chatID] = &AdminState{
-		Step:      StateViewingRequest,
-		RequestID: req.ID,
-	}
-
-	info := fmt.Sprintf(
-		"Заявка #%d\nИмя: %s\nФамилия:%s\nДата рождения: %s\nСтатус: %s\nТелефон: %s",
-		req.ID, req.FirstName, req.LastName, req.BirthDate.Format("02.01.2006"), req.UserStatus, req.PhoneNumber,
-	)
-
-	msg := tgbotapi.NewMessage(chatID, info)
-	msg.ReplyMarkup = RequestActionButtons()
-	b.botAPI.Send(msg)
-
-	if req.DocumentPath != "" {
-		doc := tgbotapi.NewDocument(chatID, tgbotapi.FilePath(req.DocumentPath))
-		b.botAPI.Send(doc)
-	}
-}
+	chatID := update.Message.Chat.ID
+	text := update.Message.Text
 
-func (b *BotService) handleMessages(chatID int64) {
-	messages, err := b.adminRepo.GetLatestMessages()
-	if err != nil {
-		log.Printf("Error loading messages: %v\n", err)
-		msg := tgbotapi.NewMessage(chatID, "Ошибка при получении сообщений")
-		b.botAPI.Send(msg)
+	// Ссылка-приглашение открывает бот ещё не админу
+	if update.Message.Chat.IsPrivate() && update.Message.Command() == "start" && update.Message.CommandArguments() != "" {
+		b.handleInvite(update.Message)
 		return
 	}
 
-	if len(messages) == 0 {
-		msg := tgbotapi.NewMessage(chatID, "Сообщений от пользователей пока нет")
-		msg.ReplyMarkup = AdminMainMenu()
+	admin, ok := b.currentAdmin(chatID)
+	if !ok {
+		msg := tgbotapi.NewMessage(chatID, "Доступ запрещен")
 		b.botAPI.Send(msg)
 		return
 	}
 
-	for _, m := range messages {
-		info := fmt.Sprintf(
-			"От пользователя %s %s (user_id %d)\nСообщение: %s\n---",
-			m.FirstName, m.LastName, m.TelegramUserID, m.Message,
-		)
-
-		msg := tgbotapi.NewMessage(chatID, info)
-		b.botAPI.Send(msg)
+	if _, exists := b.adminStates[chatID]; !exists {
+		b.adminStates[chatID] = &AdminState{Step: StateMainMenu}
 	}
 
-	msg := tgbotapi.NewMessage(chatID, "Возвращаемся в меню")
-	msg.ReplyMarkup = AdminMainMenu()
-	b.botAPI.Send(msg)
-}
-
-func (b *BotService) handleAddAdmin(chatID int64) {
-	b.adminStates[chatID].Step = StateAddingAdmin
+	state := b.adminStates[chatID]
 
-	msg := tgbotapi.NewMessage(chatID, "Введите chat_id нового админа")
-	msg.ReplyMarkup = CancelMenu()
-	b.botAPI.Send(msg)
-}
+	// Роль могли изменить, пока админ был в другом разделе
+	if permission, ok := stepPermissions[state.Step]; ok && !admin.Role.Can(permission) {
+		b.denyAccess(chatID)
+		return
+	}
 
-func (b *BotService) handleViewRequest(chatID int64, text string, botToken string) {
-	state := b.adminStates[chatID]
+	if state.Step == StateMainMenu {
+		if permission, ok := menuPermission(text); ok && !admin.Role.Can(permission) {
+			b.denyAccess(chatID)
+			return
+		}
 
-	switch text {
-	case "Одобрить":
-		err := b.registrationRepo.UpdateStatus(state.RequestID, "approved", nil)
-		if err != nil {
-			log.Printf("Error approving request: %v\n", err)
+		switch text {
+		case "/start", "Главное меню":
+			b.handleMainMenu(chatID)
+		case "Проверить заявки":
+			b.handleCheckRequests(chatID)
+		case "Очередь заявок":
+			b.handleQueue(chatID)
+		case "Сообщения пользователей":
+			b.handleMessages(chatID)
+		case "Партнёры":
+			b.handlePartners(chatID)
+		case "Уведомления":
+			b.handleNotifySettings(chatID)
+		case "Недоставленные":
+			b.handleUndelivered(chatID)
+		case "Шаблоны причин":
+			b.handleReasonTemplates(chatID)
+		case "Админы":
+			b.handleAdmins(chatID)
+		case "Журнал действий":
+			b.handleAudit(chatID)
+		default:
+			// Номер заявки вида #123 открывает её напрямую
+			if requestID, ok := parseRequestNumber(text); ok {
+				b.openRequest(chatID, requestID)
+				return
+			}
 			b.handleMainMenu(chatID)
-			return
 		}
+		return
+	}
 
-		msg := tgbotapi.NewMessage(chatID, "Заявка одобрена")
-		b.botAPI.Send(msg)
+	switch state.Step {
+	case StateEnteringRejectReason:
+		b.handleRejectReason(chatID, text)
 
-		req, err := b.registrationRepo.GetByID(state.RequestID)
-		if err == nil {
-			userBotApi, _ := tgbotapi.NewBotAPI(botToken)
+	case StateEnteringRevisionReason:
+		b.handleRevisionReason(chatID, text)
 
-			approveMessage := "Поздравляем! Ваша заявка одобрена. На языке дипломатии теперь Вы – persona grata. После оплаты вам будет предоставлен доступ в закрытый чат, приложение со специальными условиями от наших лучших партнеров, а также информация о мероприятиях сообщества. Пожалуйста, ознакомьтесь с Публичной офертой.\n"
+	case StateEnteringRequestNote:
+		b.handleRequestNote(chatID, text)
 
-			publicOffert := "*Доступ в сообщество оплачивается на 1 месяц. Подписка не продлевается автоматически и в любой момент ее можно остановить. "
-			msg := tgbotapi.NewMessage(req.TelegramUserID, approveMessage+publicOffert)
-			userBotApi.Send(msg)
+	case StateEnteringHoldNote:
+		b.handleHoldNote(chatID, text)
 
-			offertDoc := tgbotapi.NewDocument(chatID, tgbotapi.FilePath("agreements/public.docx"))
-			offertDoc.ReplyMarkup = tgbotapi.NewReplyKeyboard(
-				tgbotapi.NewKeyboardButtonRow(
-					tgbotapi.NewKeyboardButton("Оплатить"),
-					tgbotapi.NewKeyboardButton("Написать админу"),
-				),
-			)
-			userBotApi.Send(offertDoc)
-		}
+	case StateEnteringHoldReminder:
+		b.handleHoldReminder(chatID, text)
 
-		b.handleCheckRequests(chatID)
+	case StateEnteringReasonTemplate:
+		b.handleReasonTemplateInput(chatID, text)
 
-	case "Отклонить":
-		b.adminStates[chatID].Step = StateEnteringRejectReason
-		msg := tgbotapi.NewMessage(chatID, "Введите причину отклонения")
-		msg.ReplyMarkup = CancelMenu()
-		b.botAPI.Send(msg)
+	case StateEnteringCategoryTitle:
+		b.handleCategoryTitle(chatID, text)
 
-	case "На доработку":
-		b.adminStates[chatID].Step = StateEnteringRevisionReason
-		msg := tgbotapi.NewMessage(chatID, "Введите причину отправки на доработку")
-		msg.ReplyMarkup = CancelMenu()
-		b.botAPI.Send(msg)
+	case StateEnteringPartnerField:
+		b.handlePartnerFieldInput(update.Message)
 
-	case "Главное меню":
-		b.handleMainMenu(chatID)
+	case StateEnteringSupportReply:
+		b.handleSupportReply(chatID, text)
+
+	case StateEnteringAuditPeriod:
+		b.handleAuditPeriod(chatID, text)
 
 	default:
-		msg := tgbotapi.NewMessage(chatID, "Выберите действие")
-		msg.ReplyMarkup = RequestActionButtons()
-		b.botAPI.Send(msg)
+		log.Printf("Unknown state %s for chatID %d", state.Step, chatID)
+		b.handleMainMenu(chatID)
 	}
 }
 
-func (b *BotService) handleRejectReason(chatID int64, text string, botToken string) {
-	if text == "Отмена" {
-		b.handleCheckRequests(chatID)
+func (b *BotService) handleCallback(query *tgbotapi.CallbackQuery) {
+	chatID := query.From.ID
+
+	admin, ok := b.currentAdmin(chatID)
+	if !ok {
+		b.botAPI.Request(tgbotapi.NewCallback(query.ID, "Доступ запрещен"))
 		return
 	}
 
-	state := b.adminStates[chatID]
-
-	err := b.registrationRepo.UpdateStatus(state.RequestID, "rejected", pointer.ToString(text))
-	if err != nil {
-		log.Printf("Error rejecting request: %v\n", err)
-		b.handleMainMenu(chatID)
+	if permission, ok := callbackPermission(query.Data); ok && !admin.Role.Can(permission) {
+		b.botAPI.Request(tgbotapi.NewCallbackWithAlert(query.ID, "Недостаточно прав"))
 		return
 	}
 
-	msg := tgbotapi.NewMessage(chatID, "Заявка отклонена")
-	b.botAPI.Send(msg)
-
-	req, err := b.registrationRepo.GetByID(state.RequestID)
-	if err == nil {
-		userBotApi, _ := tgbotapi.NewBotAPI(botToken)
-		info := fmt.Sprintf("Ваша заявка отклонена! Причина: %s", text)
-
-		msg := tgbotapi.NewMessage(req.TelegramUserID, info)
-		msg.ReplyMarkup = tgbotapi.NewReplyKeyboard(
-			tgbotapi.NewKeyboardButtonRow(
-				tgbotapi.NewKeyboardButton("Написать админу"),
-			),
-		)
-
-		userBotApi.Send(msg)
+	if _, exists := b.adminStates[chatID]; !exists {
+		b.adminStates[chatID] = &AdminState{Step: StateMainMenu}
 	}
 
-	b.handleCheckRequests(chatID)
-}
-
-func (b *BotService) handleRevisionReason(chatID int64, text string, botToken string) {
-	if text == "Отмена" {
-		b.handleCheckRequests(chatID)
-		return
+	switch {
+	case hasCallbackPrefix(query.Data, partnersCallbackPrefix):
+		b.handlePartnersCallback(query)
+	case hasCallbackPrefix(query.Data, supportCallbackPrefix):
+		b.handleSupportCallback(query)
+	case hasCallbackPrefix(query.Data, adminnotify.CallbackPrefix):
+		b.handleOpenCallback(query)
+	case hasCallbackPrefix(query.Data, notifySettingsCallbackPrefix):
+		b.handleNotifySettingsCallback(query)
+	case hasCallbackPrefix(query.Data, outboxCallbackPrefix):
+		b.handleOutboxCallback(query)
+	case hasCallbackPrefix(query.Data, queueCallbackPrefix):
+		b.handleQueueCallback(query)
+	case hasCallbackPrefix(query.Data, reviewCallbackPrefix):
+		b.handleReviewCallback(query)
+	case hasCallbackPrefix(query.Data, reasonsCallbackPrefix):
+		b.handleReasonsCallback(query)
+	case hasCallbackPrefix(query.Data, adminsCallbackPrefix):
+		b.handleAdminsCallback(query)
+	case hasCallbackPrefix(query.Data, auditCallbackPrefix):
+		b.handleAuditCallback(query)
+	default:
+		b.botAPI.Request(tgbotapi.NewCallback(query.ID, ""))
+		log.Printf("Unknown callback %q from chatID %d", query.Data, chatID)
 	}
+}
 
-	state := b.adminStates[chatID]
+func hasCallbackPrefix(data, prefix string) bool {
+	return data == prefix || strings.HasPrefix(data, prefix+":")
+}
 
-	err := b.registrationRepo.UpdateStatus(state.RequestID, "needs_revision", pointer.ToString(text))
-	if err != nil {
-		log.Printf("Error updating to needs_revision status: %v\n", err)
-		b.handleMainMenu(chatID)
-		return
-	}
+func (b *BotService) handleMainMenu(chatID int64) {
+	b.adminStates[chatID] = &AdminState{Step: StateMainMenu}
 
-	msg := tgbotapi.NewMessage(chatID, "Заявка отправлена на доработку")
+	msg := tgbotapi.NewMessage(chatID, "Главное меню:")
+	msg.ReplyMarkup = b.mainMenu(chatID)
 	b.botAPI.Send(msg)
+}
 
-	req, err := b.registrationRepo.GetByID(state.RequestID)
-	if err == nil {
-		userBotApi, _ := tgbotapi.NewBotAPI(botToken)
-		info := fmt.Sprintf("Ваша заявка требует доработки! Причина: %s", text)
-
-		msg := tgbotapi.NewMessage(req.TelegramUserID, info)
-		msg.ReplyMarkup = tgbotapi.NewReplyKeyboard(
-			tgbotapi.NewKeyboardButtonRow(
-				tgbotapi.NewKeyboardButton("Загрузить новый документ"),
-				tgbotapi.NewKeyboardButton("Написать админу"),
-			),
-		)
-
-		userBotApi.Send(msg)
-	}
-
-	b.handleCheckRequests(chatID)
+// Следующая заявка закрепляется за админом, чтобы её не рассмотрели дважды
+func (b *BotService) handleCheckRequests(chatID int64) {
+	b.showNextRequest(chatID, 0)
 }
 
-func (b *BotService) handleAddingAdmin(chatID int64, text string) {
-	if text == "Отмена" {
-		b.handleMainMenu(chatID)
+// Закрепить и показать следующую заявку, пропустив skipID
+func (b *BotService) showNextRequest(chatID, skipID int64) {
+	admin, err := b.adminRepo.GetByChatID(chatID)
+	if err != nil {
+		log.Printf("Error loading admin %d: %v\n", chatID, err)
+		msg := tgbotapi.NewMessage(chatID, "Ошибка при получении заявок.")
+		b.botAPI.Send(msg)
 		return
 	}
 
-	newChatID, err := strconv.ParseInt(text, 10, 64)
+	req, err := b.registrationRepo.ClaimNextPending(admin.ID, b.claimTTL, skipID)
 	if err != nil {
-		msg := tgbotapi.NewMessage(chatID, "Некорректный chat_id. Введите еще раз")
-		msg.ReplyMarkup = CancelMenu()
+		log.Printf("Error loading pending request: %v\n", err)
+		msg := tgbotapi.NewMessage(chatID, "Ошибка при получении заявок.")
 		b.botAPI.Send(msg)
 		return
 	}
 
-	err = b.adminRepo.Create(newChatID)
-	if err != nil {
-		msg := tgbotapi.NewMessage(chatID, "Ошибка при добавлении админа")
-		b.botAPI.Send(msg)
-	} else {
-		msg := tgbotapi.NewMessage(chatID, "Админ успешно добавлен")
+	if req == nil {
+		msg := tgbotapi.NewMessage(chatID, "Нет новых заявок")
+		msg.ReplyMarkup = b.mainMenu(chatID)
 		b.botAPI.Send(msg)
+		b.adminStates[chatID] = &AdminState{Step: StateMainMenu}
+		return
 	}
 
-	b.handleMainMenu(chatID)
+	b.showRequest(chatID, req)
 }
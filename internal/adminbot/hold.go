@@ -0,0 +1,188 @@
+package adminbot
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/gratefultolord/ac_signup_bot/internal/adminnotify"
+	"github.com/gratefultolord/ac_signup_bot/internal/db"
+)
+
+const (
+	holdInTwoHours    = "Через 2 часа"
+	holdTomorrow      = "Завтра в 10:00"
+	holdInWeek        = "Через неделю"
+	holdWithoutRemind = "Без напоминания"
+)
+
+func HoldReminderMenu() tgbotapi.ReplyKeyboardMarkup {
+	return tgbotapi.NewReplyKeyboard(
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton(holdInTwoHours),
+			tgbotapi.NewKeyboardButton(holdTomorrow),
+		),
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton(holdInWeek),
+			tgbotapi.NewKeyboardButton(holdWithoutRemind),
+		),
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton("Отмена"),
+		),
+	)
+}
+
+func (b *BotService) handleHoldNote(chatID int64, text string) {
+	if text == "Отмена" {
+		b.finishCardInput(chatID, "Отменено")
+		return
+	}
+
+	if strings.TrimSpace(text) == "" {
+		msg := tgbotapi.NewMessage(chatID, "Заметка не может быть пустой. Введите текст")
+		msg.ReplyMarkup = CancelMenu()
+		b.botAPI.Send(msg)
+		return
+	}
+
+	state := b.adminStates[chatID]
+	state.HoldNote = text
+	state.Step = StateEnteringHoldReminder
+
+	msg := tgbotapi.NewMessage(chatID, "Когда напомнить о заявке? Выберите вариант или введите дату и время в формате ДД.ММ.ГГГГ ЧЧ:ММ")
+	msg.ReplyMarkup = HoldReminderMenu()
+	b.botAPI.Send(msg)
+}
+
+func (b *BotService) handleHoldReminder(chatID int64, text string) {
+	if text == "Отмена" {
+		b.finishCardInput(chatID, "Отменено")
+		return
+	}
+
+	state := b.adminStates[chatID]
+
+	var remindAt *time.Time
+	if text != holdWithoutRemind {
+		at, ok := parseReminder(text, time.Now())
+		if !ok {
+			msg := tgbotapi.NewMessage(chatID, "Не удалось разобрать время. Введите дату в будущем в формате ДД.ММ.ГГГГ ЧЧ:ММ")
+			msg.ReplyMarkup = HoldReminderMenu()
+			b.botAPI.Send(msg)
+			return
+		}
+		remindAt = &at
+	}
+
+	admin, err := b.adminRepo.GetByChatID(chatID)
+	if err == nil {
+		err = b.registrationRepo.Hold(state.RequestID, admin.ID, state.HoldNote, remindAt)
+	}
+
+	if err != nil {
+		b.finishCardInput(chatID, b.reviewErrorText(state.RequestID, err))
+		return
+	}
+
+	b.audit(chatID, db.AuditRequestHold, auditTargetRequest, state.RequestID, state.HoldNote)
+
+	text = fmt.Sprintf("Заявка #%d отложена", state.RequestID)
+	if remindAt != nil {
+		text += ", напомню " + remindAt.Format("02.01.2006 в 15:04")
+	}
+
+	b.finishCardInput(chatID, text)
+	b.handleCheckRequests(chatID)
+}
+
+// Время напоминания из кнопки или текста; только в будущем
+func parseReminder(text string, now time.Time) (time.Time, bool) {
+	var at time.Time
+
+	switch text {
+	case holdInTwoHours:
+		at = now.Add(2 * time.Hour)
+	case holdTomorrow:
+		tomorrow := now.AddDate(0, 0, 1)
+		at = time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 10, 0, 0, 0, now.Location())
+	case holdInWeek:
+		at = now.AddDate(0, 0, 7)
+	default:
+		parsed, err := time.ParseInLocation("02.01.2006 15:04", strings.TrimSpace(text), now.Location())
+		if err != nil {
+			return time.Time{}, false
+		}
+		at = parsed
+	}
+
+	if !at.After(now) {
+		return time.Time{}, false
+	}
+
+	return at, true
+}
+
+func holdSummary(req *db.RegistrationRequest) string {
+	var summary string
+
+	if req.HoldNote != nil {
+		summary += "\nЗаметка: " + *req.HoldNote
+	}
+
+	if req.RemindAt != nil {
+		summary += "\nНапоминание: " + req.RemindAt.Format("02.01.2006 15:04")
+	}
+
+	return summary
+}
+
+// RunHoldReminders возвращает в работу отложенные заявки, у которых наступило
+// время напоминания, и сообщает об этом отложившему админу
+func (b *BotService) RunHoldReminders(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		b.resumeDueHolds()
+		<-ticker.C
+	}
+}
+
+func (b *BotService) resumeDueHolds() {
+	resumed, err := b.registrationRepo.ResumeDue()
+	if err != nil {
+		log.Printf("Error resuming held requests: %v\n", err)
+		return
+	}
+
+	for _, req := range resumed {
+		// Админа могли удалить: тогда заявка просто вернулась в общую очередь
+		if req.HeldBy == nil {
+			continue
+		}
+
+		admin, err := b.adminRepo.GetByID(*req.HeldBy)
+		if err != nil {
+			log.Printf("Error loading admin %d: %v\n", *req.HeldBy, err)
+			continue
+		}
+
+		text := fmt.Sprintf("⏰ Заявка #%d %s %s снова на проверке", req.ID, req.FirstName, req.LastName)
+		if req.HoldNote != nil {
+			text += "\nЗаметка: " + *req.HoldNote
+		}
+
+		msg := tgbotapi.NewMessage(admin.ChatID, text)
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("Открыть", adminnotify.OpenRequest(req.ID)),
+			),
+		)
+		if _, err := b.botAPI.Send(msg); err != nil {
+			log.Printf("Error sending hold reminder to %d: %v\n", admin.ChatID, err)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package adminbot
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseReminder(t *testing.T) {
+	loc := time.FixedZone("MSK", 3*60*60)
+	now := time.Date(2026, 3, 31, 18, 30, 0, 0, loc)
+
+	tests := []struct {
+		name string
+		text string
+		want time.Time
+		ok   bool
+	}{
+		{name: "in two hours", text: holdInTwoHours, want: now.Add(2 * time.Hour), ok: true},
+		{name: "tomorrow at ten", text: holdTomorrow, want: time.Date(2026, 4, 1, 10, 0, 0, 0, loc), ok: true},
+		{name: "in a week", text: holdInWeek, want: time.Date(2026, 4, 7, 18, 30, 0, 0, loc), ok: true},
+		{name: "exact date", text: "02.04.2026 09:15", want: time.Date(2026, 4, 2, 9, 15, 0, 0, loc), ok: true},
+		{name: "exact date with spaces", text: "  02.04.2026 09:15 ", want: time.Date(2026, 4, 2, 9, 15, 0, 0, loc), ok: true},
+		{name: "now is not in the future", text: "31.03.2026 18:30"},
+		{name: "past date", text: "30.03.2026 12:00"},
+		{name: "date without time", text: "02.04.2026"},
+		{name: "invalid day", text: "32.04.2026 10:00"},
+		{name: "free text", text: "когда-нибудь"},
+		{name: "empty", text: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseReminder(tt.text, now)
+			if ok != tt.ok {
+				t.Fatalf("parseReminder(%q) ok = %v, want %v", tt.text, ok, tt.ok)
+			}
+
+			if ok && !got.Equal(tt.want) {
+				t.Fatalf("parseReminder(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package adminbot
+
+import (
+	"log"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/gratefultolord/ac_signup_bot/internal/access"
+	"github.com/gratefultolord/ac_signup_bot/internal/adminnotify"
+	"github.com/gratefultolord/ac_signup_bot/internal/db"
+)
+
+const notifySettingsCallbackPrefix = "notify"
+
+// Кнопка «Открыть» в уведомлении: open:requests, open:conversations,
+// open:request:<id>, open:conversation:<id>
+func (b *BotService) handleOpenCallback(query *tgbotapi.CallbackQuery) {
+	b.botAPI.Request(tgbotapi.NewCallback(query.ID, ""))
+
+	chatID := query.From.ID
+
+	switch query.Data {
+	case adminnotify.OpenRequests:
+		b.handleCheckRequests(chatID)
+		return
+	case adminnotify.OpenConversations:
+		b.showConversations(chatID, nil, db.ConversationOpen)
+		return
+	}
+
+	parts := strings.Split(query.Data, ":")
+	if len(parts) != 3 {
+		log.Printf("bad open callback %q", query.Data)
+		return
+	}
+
+	id, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		log.Printf("bad open callback %q", query.Data)
+		return
+	}
+
+	switch parts[1] {
+	case "request":
+		b.openRequest(chatID, id)
+
+	case "conversation":
+		b.showConversation(chatID, nil, id)
+
+	default:
+		log.Printf("bad open callback %q", query.Data)
+	}
+}
+
+func (b *BotService) handleNotifySettings(chatID int64) {
+	text, keyboard, err := b.notifySettingsView(chatID)
+	if err != nil {
+		log.Printf("Error loading notification settings: %v\n", err)
+		msg := tgbotapi.NewMessage(chatID, "Ошибка при загрузке настроек")
+		b.botAPI.Send(msg)
+		return
+	}
+
+	b.showInlineView(chatID, nil, text, keyboard)
+}
+
+// notify:requests и notify:messages переключают соответствующие уведомления
+func (b *BotService) handleNotifySettingsCallback(query *tgbotapi.CallbackQuery) {
+	b.botAPI.Request(tgbotapi.NewCallback(query.ID, ""))
+
+	if query.Message == nil {
+		return
+	}
+
+	chatID := query.Message.Chat.ID
+
+	admin, err := b.adminRepo.GetByChatID(chatID)
+	if err != nil {
+		log.Printf("Error loading notification settings: %v\n", err)
+		return
+	}
+
+	requests, messages := admin.NotifyRequests, admin.NotifyMessages
+
+	switch query.Data {
+	case notifySettingsCallbackPrefix + ":requests":
+		requests = !requests
+	case notifySettingsCallbackPrefix + ":messages":
+		messages = !messages
+	default:
+		log.Printf("bad notify callback %q", query.Data)
+		return
+	}
+
+	if err := b.adminRepo.SetNotifications(chatID, requests, messages); err != nil {
+		log.Printf("Error saving notification settings: %v\n", err)
+		msg := tgbotapi.NewMessage(chatID, "Не удалось сохранить настройки")
+		b.botAPI.Send(msg)
+		return
+	}
+
+	text, keyboard, err := b.notifySettingsView(chatID)
+	if err != nil {
+		log.Printf("Error loading notification settings: %v\n", err)
+		return
+	}
+
+	b.showInlineView(chatID, query.Message, text, keyboard)
+}
+
+func (b *BotService) notifySettingsView(chatID int64) (string, tgbotapi.InlineKeyboardMarkup, error) {
+	admin, err := b.adminRepo.GetByChatID(chatID)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, err
+	}
+
+	// Переключатели только для разделов, доступных роли админа
+	text := "Уведомления в этом чате:"
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+
+	if admin.Role.Can(access.ReviewRequests) {
+		text += "\nНовые заявки: " + onOff(admin.NotifyRequests)
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(toggleTitle(admin.NotifyRequests, "заявки"), notifySettingsCallbackPrefix+":requests"),
+		))
+	}
+
+	if admin.Role.Can(access.AnswerSupport) {
+		text += "\nСообщения пользователей: " + onOff(admin.NotifyMessages)
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(toggleTitle(admin.NotifyMessages, "сообщения"), notifySettingsCallbackPrefix+":messages"),
+		))
+	}
+
+	if len(rows) == 0 {
+		text = "Для вашей роли уведомлений нет"
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+
+	return text, keyboard, nil
+}
+
+func onOff(enabled bool) string {
+	if enabled {
+		return "включены"
+	}
+	return "выключены"
+}
+
+func toggleTitle(enabled bool, what string) string {
+	if enabled {
+		return "🔕 Выключить " + what
+	}
+	return "🔔 Включить " + what
+}
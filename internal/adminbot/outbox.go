@@ -0,0 +1,138 @@
+package adminbot
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/gratefultolord/ac_signup_bot/internal/db"
+	"github.com/gratefultolord/ac_signup_bot/internal/events"
+	"github.com/gratefultolord/ac_signup_bot/internal/lifecycle"
+)
+
+const (
+	outboxCallbackPrefix = "outbox"
+
+	outboxListLimit  = 15
+	outboxErrorLimit = 120
+)
+
+var notificationKindTitles = map[string]string{
+	string(lifecycle.Approved):      "одобрение",
+	string(lifecycle.Rejected):      "отклонение",
+	string(lifecycle.NeedsRevision): "доработка",
+	db.NotificationSupportReply:     "ответ в обращении",
+	db.NotificationSupportClosed:    "закрытие обращения",
+}
+
+func (b *BotService) handleUndelivered(chatID int64) {
+	b.showUndelivered(chatID, nil)
+}
+
+// Обработка нажатий в списке недоставленных: outbox — обновить, outbox:retry:<id>
+func (b *BotService) handleOutboxCallback(query *tgbotapi.CallbackQuery) {
+	if query.Message == nil {
+		b.botAPI.Request(tgbotapi.NewCallback(query.ID, ""))
+		return
+	}
+
+	chatID := query.Message.Chat.ID
+
+	parts := strings.Split(query.Data, ":")
+	if len(parts) == 1 {
+		b.botAPI.Request(tgbotapi.NewCallback(query.ID, ""))
+		b.showUndelivered(chatID, query.Message)
+		return
+	}
+
+	if len(parts) != 3 || parts[1] != "retry" {
+		b.botAPI.Request(tgbotapi.NewCallback(query.ID, ""))
+		log.Printf("bad outbox callback %q", query.Data)
+		return
+	}
+
+	notificationID, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		b.botAPI.Request(tgbotapi.NewCallback(query.ID, ""))
+		log.Printf("bad outbox callback %q", query.Data)
+		return
+	}
+
+	if err := b.outboxRepo.Retry(notificationID); err != nil {
+		log.Printf("Error requeueing notification %d: %v\n", notificationID, err)
+		b.botAPI.Request(tgbotapi.NewCallback(query.ID, "Не удалось вернуть в очередь"))
+		return
+	}
+
+	b.publish(events.Event{Type: events.NotificationRequeued})
+	b.audit(chatID, db.AuditOutboxRetry, auditTargetNotification, notificationID, "")
+	b.botAPI.Request(tgbotapi.NewCallback(query.ID, "Отправка повторена"))
+
+	b.showUndelivered(chatID, query.Message)
+}
+
+func (b *BotService) showUndelivered(chatID int64, current *tgbotapi.Message) {
+	notifications, err := b.outboxRepo.ListUndelivered(db.Page{Limit: outboxListLimit})
+	if err != nil {
+		log.Printf("Error loading undelivered notifications: %v\n", err)
+		msg := tgbotapi.NewMessage(chatID, "Ошибка при получении уведомлений")
+		b.botAPI.Send(msg)
+		return
+	}
+
+	total, err := b.outboxRepo.CountUndelivered()
+	if err != nil {
+		log.Printf("Error counting undelivered notifications: %v\n", err)
+		total = len(notifications)
+	}
+
+	refresh := tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("Обновить", outboxCallbackPrefix),
+	)
+
+	if len(notifications) == 0 {
+		b.showInlineView(chatID, current, "Недоставленных уведомлений нет", tgbotapi.NewInlineKeyboardMarkup(refresh))
+		return
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Недоставленные уведомления: %d\n", total)
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, n := range notifications {
+		state := fmt.Sprintf("повтор в %s", n.NextAttemptAt.Format("15:04"))
+		if n.Status == db.OutboxDead {
+			state = "доставка прекращена"
+		}
+
+		lastError := "—"
+		if n.LastError != nil {
+			lastError = html.EscapeString(truncateText(*n.LastError, outboxErrorLimit))
+		}
+
+		subject := "Заявка —"
+		switch {
+		case n.RequestID != nil:
+			subject = fmt.Sprintf("Заявка #%d", *n.RequestID)
+		case n.ConversationID != nil:
+			subject = fmt.Sprintf("Обращение #%d", *n.ConversationID)
+		}
+
+		fmt.Fprintf(&sb, "\n%d. %s, %s, пользователь %d\nПопыток: %d, %s\nОшибка: %s\n",
+			n.ID, subject, notificationKindTitles[n.Kind], n.TelegramUserID, n.Attempts, state, lastError)
+
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("Повторить %d", n.ID),
+				fmt.Sprintf("%s:retry:%d", outboxCallbackPrefix, n.ID),
+			),
+		))
+	}
+	rows = append(rows, refresh)
+
+	b.showInlineView(chatID, current, sb.String(), tgbotapi.NewInlineKeyboardMarkup(rows...))
+}
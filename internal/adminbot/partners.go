@@ -0,0 +1,712 @@
+package adminbot
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/AlekSi/pointer"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/gratefultolord/ac_signup_bot/internal/catalog"
+	"github.com/gratefultolord/ac_signup_bot/internal/db"
+)
+
+const (
+	partnersCallbackPrefix = "partners"
+
+	// Категорий и партнёров в категории немного, показываем их одной клавиатурой
+	partnersListLimit = 50
+
+	// Подпись к фото в Telegram ограничена 1024 символами, оставляем место под остальные поля
+	maxDescriptionLength = 700
+)
+
+// Поля партнёра в порядке их заполнения при создании
+var partnerFields = []string{"title", "description", "address", "url", "discount", "photo"}
+
+var partnerFieldPrompts = map[string]string{
+	"title":       "Введите название партнёра",
+	"description": fmt.Sprintf("Введите описание (до %d символов)", maxDescriptionLength),
+	"address":     "Введите адрес",
+	"url":         "Введите ссылку на сайт партнёра",
+	"discount":    "Введите скидку в процентах (например, 15%) или в рублях (например, 500 ₽)",
+	"photo":       "Отправьте фотографию партнёра",
+}
+
+var (
+	percentDiscountRe = regexp.MustCompile(`^(\d+(?:[.,]\d+)?)\s*%$`)
+	fixedDiscountRe   = regexp.MustCompile(`^(\d+)\s*(?:₽|р\.?|руб\.?)?$`)
+)
+
+func (b *BotService) handlePartners(chatID int64) {
+	text, keyboard, err := b.categoriesListView()
+	if err != nil {
+		log.Printf("Error loading categories: %v\n", err)
+		msg := tgbotapi.NewMessage(chatID, "Ошибка при загрузке каталога")
+		b.botAPI.Send(msg)
+		return
+	}
+
+	b.showInlineView(chatID, nil, text, keyboard)
+}
+
+// Обработка нажатий в разделе «Партнёры»:
+// partners — список категорий, partners:<action>:<id>[:<field>] — действие над категорией или партнёром
+func (b *BotService) handlePartnersCallback(query *tgbotapi.CallbackQuery) {
+	b.botAPI.Request(tgbotapi.NewCallback(query.ID, ""))
+
+	if query.Message == nil {
+		return
+	}
+
+	chatID := query.Message.Chat.ID
+	state := b.adminStates[chatID]
+
+	// Нажатие кнопки прерывает незавершённый ввод
+	if state.Step == StateEnteringCategoryTitle || state.Step == StateEnteringPartnerField {
+		b.adminStates[chatID] = &AdminState{Step: StateMainMenu}
+		state = b.adminStates[chatID]
+	}
+
+	parts := strings.Split(query.Data, ":")
+	if len(parts) == 1 {
+		b.showCategoriesList(chatID, query.Message)
+		return
+	}
+
+	action := parts[1]
+
+	if action == "newcat" {
+		state.Step = StateEnteringCategoryTitle
+		state.CategoryID = 0
+
+		msg := tgbotapi.NewMessage(chatID, "Введите название новой категории")
+		msg.ReplyMarkup = CancelMenu()
+		b.botAPI.Send(msg)
+		return
+	}
+
+	if len(parts) < 3 {
+		log.Printf("bad partners callback %q", query.Data)
+		return
+	}
+
+	id, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		log.Printf("bad partners callback %q", query.Data)
+		return
+	}
+
+	switch action {
+	case "cat":
+		b.showCategory(chatID, query.Message, id)
+
+	case "rencat":
+		state.Step = StateEnteringCategoryTitle
+		state.CategoryID = id
+
+		msg := tgbotapi.NewMessage(chatID, "Введите новое название категории")
+		msg.ReplyMarkup = CancelMenu()
+		b.botAPI.Send(msg)
+
+	case "togglecat":
+		category, err := b.categoryRepo.GetByID(id)
+		if err != nil {
+			b.reportCatalogError(chatID, err)
+			return
+		}
+
+		if err := b.categoryRepo.SetPublished(id, !category.IsPublished); err != nil {
+			b.reportCatalogError(chatID, err)
+			return
+		}
+
+		b.audit(chatID, db.AuditCatalogChange, auditTargetCategory, id, publishedChange(!category.IsPublished))
+
+		b.showCategory(chatID, query.Message, id)
+
+	case "delcat":
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("Да, удалить", fmt.Sprintf("%s:delcatok:%d", partnersCallbackPrefix, id)),
+				tgbotapi.NewInlineKeyboardButtonData("Отмена", fmt.Sprintf("%s:cat:%d", partnersCallbackPrefix, id)),
+			),
+		)
+		b.showInlineView(chatID, query.Message, "Удалить категорию вместе со всеми её партнёрами?", keyboard)
+
+	case "delcatok":
+		b.deleteCategory(chatID, query.Message, id)
+
+	case "newp":
+		state.Step = StateEnteringPartnerField
+		state.CategoryID = id
+		state.PartnerID = 0
+		state.Field = partnerFields[0]
+		state.Creating = true
+
+		b.promptPartnerField(chatID, state)
+
+	case "p":
+		b.showPartnerPreview(chatID, query.Message, id)
+
+	case "edit":
+		if len(parts) != 4 || partnerFieldPrompts[parts[3]] == "" {
+			log.Printf("bad partners callback %q", query.Data)
+			return
+		}
+
+		partner, err := b.partnerRepo.GetByID(id)
+		if err != nil {
+			b.reportCatalogError(chatID, err)
+			return
+		}
+
+		state.Step = StateEnteringPartnerField
+		state.CategoryID = partner.CategoryID
+		state.PartnerID = partner.ID
+		state.Field = parts[3]
+		state.Creating = false
+
+		b.promptPartnerField(chatID, state)
+
+	case "toggle":
+		partner, err := b.partnerRepo.GetByID(id)
+		if err != nil {
+			b.reportCatalogError(chatID, err)
+			return
+		}
+
+		if err := b.partnerRepo.SetPublished(id, !partner.IsPublished); err != nil {
+			b.reportCatalogError(chatID, err)
+			return
+		}
+
+		b.audit(chatID, db.AuditCatalogChange, auditTargetPartner, id, publishedChange(!partner.IsPublished))
+
+		b.showPartnerPreview(chatID, query.Message, id)
+
+	case "delp":
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("Да, удалить", fmt.Sprintf("%s:delpok:%d", partnersCallbackPrefix, id)),
+				tgbotapi.NewInlineKeyboardButtonData("Отмена", fmt.Sprintf("%s:p:%d", partnersCallbackPrefix, id)),
+			),
+		)
+		b.showInlineView(chatID, query.Message, "Удалить партнёра?", keyboard)
+
+	case "delpok":
+		b.deletePartner(chatID, query.Message, id)
+
+	default:
+		log.Printf("bad partners callback %q", query.Data)
+	}
+}
+
+func (b *BotService) handleCategoryTitle(chatID int64, text string) {
+	state := b.adminStates[chatID]
+	categoryID := state.CategoryID
+
+	if text == "Отмена" {
+		b.adminStates[chatID] = &AdminState{Step: StateMainMenu}
+		b.restoreMainMenu(chatID, "Отменено")
+
+		if categoryID != 0 {
+			b.showCategory(chatID, nil, categoryID)
+		} else {
+			b.showCategoriesList(chatID, nil)
+		}
+		return
+	}
+
+	category := &db.Category{Title: strings.TrimSpace(text), IsPublished: true}
+
+	var err error
+	if categoryID == 0 {
+		err = b.categoryRepo.Create(category)
+	} else {
+		category, err = b.categoryRepo.GetByID(categoryID)
+		if err == nil {
+			category.Title = strings.TrimSpace(text)
+			err = b.categoryRepo.Update(category)
+		}
+	}
+
+	if err != nil {
+		log.Printf("Error saving category: %v\n", err)
+		msg := tgbotapi.NewMessage(chatID, "Не удалось сохранить категорию. Введите название еще раз")
+		msg.ReplyMarkup = CancelMenu()
+		b.botAPI.Send(msg)
+		return
+	}
+
+	change := "создана: "
+	if categoryID != 0 {
+		change = "переименована: "
+	}
+	b.audit(chatID, db.AuditCatalogChange, auditTargetCategory, category.ID, change+category.Title)
+
+	b.adminStates[chatID] = &AdminState{Step: StateMainMenu}
+	b.restoreMainMenu(chatID, "Категория сохранена")
+	b.showCategory(chatID, nil, category.ID)
+}
+
+// Ввод значения поля партнёра: при создании поля заполняются по очереди,
+// при редактировании после ввода показывается обновлённая карточка
+func (b *BotService) handlePartnerFieldInput(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	state := b.adminStates[chatID]
+	text := strings.TrimSpace(message.Text)
+
+	if text == "Отмена" {
+		b.finishPartnerEditing(chatID, state)
+		return
+	}
+
+	partner := &db.Partner{CategoryID: state.CategoryID}
+	if state.PartnerID != 0 {
+		var err error
+		partner, err = b.partnerRepo.GetByID(state.PartnerID)
+		if err != nil {
+			b.reportCatalogError(chatID, err)
+			b.adminStates[chatID] = &AdminState{Step: StateMainMenu}
+			return
+		}
+	}
+
+	skip := state.Field != "title" && (text == "Пропустить" || text == "Очистить")
+
+	var oldPhoto string
+	if state.Field == "photo" && !skip {
+		if len(message.Photo) == 0 {
+			b.repeatPartnerField(chatID, state, "Отправьте фотографию, а не файл или текст")
+			return
+		}
+
+		path, err := b.photoService.SaveFile(message.Photo[len(message.Photo)-1].FileID)
+		if err != nil {
+			log.Printf("Error saving partner photo: %v\n", err)
+			b.repeatPartnerField(chatID, state, "Не удалось сохранить фото. Попробуйте еще раз")
+			return
+		}
+
+		oldPhoto = catalog.ResolvePhoto(partner.PhotoPath)
+		partner.PhotoPath = pointer.ToString(path)
+	} else if state.Field == "photo" {
+		oldPhoto = catalog.ResolvePhoto(partner.PhotoPath)
+		partner.PhotoPath = nil
+	} else if problem := setPartnerField(partner, state.Field, text, skip); problem != "" {
+		b.repeatPartnerField(chatID, state, problem)
+		return
+	}
+
+	change := "изменено поле " + state.Field
+
+	var err error
+	if partner.ID == 0 {
+		change = "создан: " + partner.Title
+		err = b.partnerRepo.Create(partner)
+	} else {
+		err = b.partnerRepo.Update(partner)
+	}
+
+	if err != nil {
+		log.Printf("Error saving partner: %v\n", err)
+		b.repeatPartnerField(chatID, state, "Не удалось сохранить партнёра. Попробуйте еще раз")
+		return
+	}
+
+	b.audit(chatID, db.AuditCatalogChange, auditTargetPartner, partner.ID, change)
+
+	if oldPhoto != "" {
+		if err := b.photoService.DeleteFile(oldPhoto); err != nil {
+			log.Printf("Error deleting old partner photo: %v\n", err)
+		}
+	}
+
+	state.PartnerID = partner.ID
+
+	if state.Creating {
+		for i, field := range partnerFields[:len(partnerFields)-1] {
+			if field == state.Field {
+				state.Field = partnerFields[i+1]
+				b.promptPartnerField(chatID, state)
+				return
+			}
+		}
+	}
+
+	b.finishPartnerEditing(chatID, state)
+}
+
+// Применить введённое значение к полю партнёра. Возвращает текст ошибки для админа
+func setPartnerField(partner *db.Partner, field, text string, clear bool) string {
+	var value *string
+	if !clear && text != "" {
+		value = pointer.ToString(text)
+	}
+
+	switch field {
+	case "title":
+		if text == "" {
+			return "Название не может быть пустым"
+		}
+		partner.Title = text
+
+	case "description":
+		if utf8.RuneCountInString(text) > maxDescriptionLength {
+			return fmt.Sprintf("Описание слишком длинное: не больше %d символов", maxDescriptionLength)
+		}
+		partner.Description = value
+
+	case "address":
+		partner.Address = value
+
+	case "url":
+		if value != nil {
+			link, ok := normalizeURL(text)
+			if !ok {
+				return "Некорректная ссылка. Пример: https://example.com"
+			}
+			value = pointer.ToString(link)
+		}
+		partner.URL = value
+
+	case "discount":
+		partner.DiscountType = nil
+		partner.DiscountPercentSize = nil
+		partner.DiscountFixedSize = nil
+
+		if value == nil {
+			return ""
+		}
+
+		if m := percentDiscountRe.FindStringSubmatch(text); m != nil {
+			size, err := strconv.ParseFloat(strings.Replace(m[1], ",", ".", 1), 64)
+			if err != nil || size <= 0 || size > 100 {
+				return "Скидка в процентах должна быть от 0 до 100"
+			}
+			partner.DiscountType = pointer.ToString(db.DiscountPercent)
+			partner.DiscountPercentSize = pointer.ToFloat64(size)
+			return ""
+		}
+
+		if m := fixedDiscountRe.FindStringSubmatch(text); m != nil {
+			size, err := strconv.ParseInt(m[1], 10, 64)
+			if err != nil || size <= 0 {
+				return "Скидка в рублях должна быть больше нуля"
+			}
+			partner.DiscountType = pointer.ToString(db.DiscountFixed)
+			partner.DiscountFixedSize = pointer.ToInt64(size)
+			return ""
+		}
+
+		return "Не удалось распознать скидку. Пример: 15% или 500 ₽"
+	}
+
+	return ""
+}
+
+func normalizeURL(text string) (string, bool) {
+	if !strings.Contains(text, "://") {
+		text = "https://" + text
+	}
+
+	u, err := url.ParseRequestURI(text)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || !strings.Contains(u.Host, ".") {
+		return "", false
+	}
+
+	return u.String(), true
+}
+
+func (b *BotService) promptPartnerField(chatID int64, state *AdminState) {
+	msg := tgbotapi.NewMessage(chatID, partnerFieldPrompts[state.Field])
+	if state.Field == "title" {
+		msg.ReplyMarkup = CancelMenu()
+	} else {
+		msg.ReplyMarkup = OptionalFieldMenu(state.Creating)
+	}
+	b.botAPI.Send(msg)
+}
+
+func (b *BotService) repeatPartnerField(chatID int64, state *AdminState, problem string) {
+	msg := tgbotapi.NewMessage(chatID, problem)
+	if state.Field == "title" {
+		msg.ReplyMarkup = CancelMenu()
+	} else {
+		msg.ReplyMarkup = OptionalFieldMenu(state.Creating)
+	}
+	b.botAPI.Send(msg)
+}
+
+// Завершить ввод: показать карточку партнёра или, если он ещё не создан, категорию
+func (b *BotService) finishPartnerEditing(chatID int64, state *AdminState) {
+	partnerID, categoryID := state.PartnerID, state.CategoryID
+	b.adminStates[chatID] = &AdminState{Step: StateMainMenu}
+
+	if partnerID == 0 {
+		b.restoreMainMenu(chatID, "Отменено")
+		b.showCategory(chatID, nil, categoryID)
+		return
+	}
+
+	b.showPartnerPreview(chatID, nil, partnerID)
+}
+
+func (b *BotService) showCategoriesList(chatID int64, current *tgbotapi.Message) {
+	text, keyboard, err := b.categoriesListView()
+	if err != nil {
+		b.reportCatalogError(chatID, err)
+		return
+	}
+
+	b.showInlineView(chatID, current, text, keyboard)
+}
+
+func (b *BotService) categoriesListView() (string, tgbotapi.InlineKeyboardMarkup, error) {
+	categories, err := b.categoryRepo.List(db.Page{Limit: partnersListLimit})
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, err
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, category := range categories {
+		count, err := b.partnerRepo.CountByCategory(category.ID)
+		if err != nil {
+			return "", tgbotapi.InlineKeyboardMarkup{}, err
+		}
+
+		title := fmt.Sprintf("%s (%d)", category.Title, count)
+		if !category.IsPublished {
+			title = "🙈 " + title
+		}
+
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(title, fmt.Sprintf("%s:cat:%d", partnersCallbackPrefix, category.ID)),
+		))
+	}
+
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("➕ Новая категория", partnersCallbackPrefix+":newcat"),
+	))
+
+	text := "Каталог партнёров. Выберите категорию:"
+	if len(categories) == 0 {
+		text = "Категорий пока нет"
+	}
+
+	return text, tgbotapi.NewInlineKeyboardMarkup(rows...), nil
+}
+
+func (b *BotService) showCategory(chatID int64, current *tgbotapi.Message, categoryID int64) {
+	category, err := b.categoryRepo.GetByID(categoryID)
+	if err != nil {
+		b.reportCatalogError(chatID, err)
+		return
+	}
+
+	partners, err := b.partnerRepo.ListByCategory(categoryID, db.Page{Limit: partnersListLimit})
+	if err != nil {
+		b.reportCatalogError(chatID, err)
+		return
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	published := 0
+	for _, partner := range partners {
+		title := partner.Title
+		if partner.IsPublished {
+			published++
+		} else {
+			title = "🙈 " + title
+		}
+
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(title, fmt.Sprintf("%s:p:%d", partnersCallbackPrefix, partner.ID)),
+		))
+	}
+
+	toggle := "🙈 Скрыть категорию"
+	status := "видна участникам"
+	if !category.IsPublished {
+		toggle = "👁 Показать категорию"
+		status = "скрыта"
+	}
+
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("➕ Добавить партнёра", fmt.Sprintf("%s:newp:%d", partnersCallbackPrefix, category.ID)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✏️ Переименовать", fmt.Sprintf("%s:rencat:%d", partnersCallbackPrefix, category.ID)),
+			tgbotapi.NewInlineKeyboardButtonData(toggle, fmt.Sprintf("%s:togglecat:%d", partnersCallbackPrefix, category.ID)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🗑 Удалить категорию", fmt.Sprintf("%s:delcat:%d", partnersCallbackPrefix, category.ID)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("« К категориям", partnersCallbackPrefix),
+		),
+	)
+
+	text := fmt.Sprintf(
+		"Категория «%s»\nСтатус: %s\nПартнёров: %d, опубликовано: %d",
+		html.EscapeString(category.Title), status, len(partners), published,
+	)
+
+	b.showInlineView(chatID, current, text, tgbotapi.NewInlineKeyboardMarkup(rows...))
+}
+
+// Карточка партнёра в том виде, в каком её увидят участники, с кнопками управления
+func (b *BotService) showPartnerPreview(chatID int64, current *tgbotapi.Message, partnerID int64) {
+	partner, err := b.partnerRepo.GetByID(partnerID)
+	if err != nil {
+		b.reportCatalogError(chatID, err)
+		return
+	}
+
+	if current != nil {
+		b.botAPI.Request(tgbotapi.NewDeleteMessage(chatID, current.MessageID))
+	}
+
+	status := "опубликована"
+	toggle := "🙈 Скрыть"
+	if !partner.IsPublished {
+		status = "не опубликована, участники её не видят"
+		toggle = "✅ Опубликовать"
+	}
+
+	b.restoreMainMenu(chatID, fmt.Sprintf("Так карточку увидят участники. Статус: %s", status))
+
+	editButton := func(title, field string) tgbotapi.InlineKeyboardButton {
+		return tgbotapi.NewInlineKeyboardButtonData(title, fmt.Sprintf("%s:edit:%d:%s", partnersCallbackPrefix, partner.ID, field))
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(editButton("Название", "title"), editButton("Описание", "description")),
+		tgbotapi.NewInlineKeyboardRow(editButton("Адрес", "address"), editButton("Ссылка", "url")),
+		tgbotapi.NewInlineKeyboardRow(editButton("Скидка", "discount"), editButton("Фото", "photo")),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(toggle, fmt.Sprintf("%s:toggle:%d", partnersCallbackPrefix, partner.ID)),
+			tgbotapi.NewInlineKeyboardButtonData("🗑 Удалить", fmt.Sprintf("%s:delp:%d", partnersCallbackPrefix, partner.ID)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("« К категории", fmt.Sprintf("%s:cat:%d", partnersCallbackPrefix, partner.CategoryID)),
+		),
+	)
+
+	card := catalog.FormatPartnerCard(partner)
+
+	// Длинная карточка не влезает в подпись, её показываем текстом, как и участникам
+	if photoPath := catalog.ResolvePhoto(partner.PhotoPath); photoPath != "" && catalog.FitsCaption(card) {
+		photo := tgbotapi.NewPhoto(chatID, tgbotapi.FilePath(photoPath))
+		photo.Caption = card
+		photo.ParseMode = tgbotapi.ModeHTML
+		photo.ReplyMarkup = keyboard
+		if _, err := b.botAPI.Send(photo); err != nil {
+			log.Printf("Error sending partner preview: %v\n", err)
+		}
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, card)
+	msg.ParseMode = tgbotapi.ModeHTML
+	msg.ReplyMarkup = keyboard
+	if _, err := b.botAPI.Send(msg); err != nil {
+		log.Printf("Error sending partner preview: %v\n", err)
+	}
+}
+
+func (b *BotService) deleteCategory(chatID int64, current *tgbotapi.Message, categoryID int64) {
+	partners, err := b.partnerRepo.ListByCategory(categoryID, db.Page{Limit: partnersListLimit})
+	if err != nil {
+		b.reportCatalogError(chatID, err)
+		return
+	}
+
+	if err := b.categoryRepo.Delete(categoryID); err != nil {
+		b.reportCatalogError(chatID, err)
+		return
+	}
+
+	b.audit(chatID, db.AuditCatalogChange, auditTargetCategory, categoryID, fmt.Sprintf("удалена вместе с партнёрами: %d", len(partners)))
+
+	for _, partner := range partners {
+		b.deletePartnerPhoto(&partner)
+	}
+
+	b.showCategoriesList(chatID, current)
+}
+
+func (b *BotService) deletePartner(chatID int64, current *tgbotapi.Message, partnerID int64) {
+	partner, err := b.partnerRepo.GetByID(partnerID)
+	if err != nil {
+		b.reportCatalogError(chatID, err)
+		return
+	}
+
+	if err := b.partnerRepo.Delete(partnerID); err != nil {
+		b.reportCatalogError(chatID, err)
+		return
+	}
+
+	b.audit(chatID, db.AuditCatalogChange, auditTargetPartner, partnerID, "удалён: "+partner.Title)
+
+	b.deletePartnerPhoto(partner)
+	b.showCategory(chatID, current, partner.CategoryID)
+}
+
+func publishedChange(published bool) string {
+	if published {
+		return "публикация включена"
+	}
+	return "публикация выключена"
+}
+
+func (b *BotService) deletePartnerPhoto(partner *db.Partner) {
+	if err := b.photoService.DeleteFile(catalog.ResolvePhoto(partner.PhotoPath)); err != nil {
+		log.Printf("Error deleting partner photo: %v\n", err)
+	}
+}
+
+// Показать текстовое сообщение с inline-клавиатурой, по возможности отредактировав текущее.
+// Карточку с фото нельзя превратить в текст: в этом случае сообщение пересоздаётся
+func (b *BotService) showInlineView(chatID int64, current *tgbotapi.Message, text string, keyboard tgbotapi.InlineKeyboardMarkup) {
+	if current != nil {
+		if len(current.Photo) == 0 {
+			edit := tgbotapi.NewEditMessageTextAndMarkup(chatID, current.MessageID, text, keyboard)
+			edit.ParseMode = tgbotapi.ModeHTML
+			if _, err := b.botAPI.Request(edit); err == nil {
+				return
+			}
+		}
+
+		b.botAPI.Request(tgbotapi.NewDeleteMessage(chatID, current.MessageID))
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = tgbotapi.ModeHTML
+	msg.ReplyMarkup = keyboard
+	if _, err := b.botAPI.Send(msg); err != nil {
+		log.Printf("Error sending inline view: %v\n", err)
+	}
+}
+
+// Вернуть клавиатуру главного меню после пошагового ввода
+func (b *BotService) restoreMainMenu(chatID int64, text string) {
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = b.mainMenu(chatID)
+	b.botAPI.Send(msg)
+}
+
+func (b *BotService) reportCatalogError(chatID int64, err error) {
+	log.Printf("Error managing catalog: %v\n", err)
+	msg := tgbotapi.NewMessage(chatID, "Ошибка при работе с каталогом")
+	b.botAPI.Send(msg)
+}
@@ -0,0 +1,269 @@
+package adminbot
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/gratefultolord/ac_signup_bot/internal/db"
+	"github.com/gratefultolord/ac_signup_bot/internal/lifecycle"
+)
+
+const (
+	queueCallbackPrefix = "queue"
+
+	queuePageSize = 10
+	queueAll      = "all"
+)
+
+// Разделы очереди и их названия на кнопках
+var queueStatuses = []lifecycle.Status{
+	lifecycle.Pending,
+	lifecycle.NeedsRevision,
+	lifecycle.OnHold,
+	lifecycle.Approved,
+}
+
+var queueStatusTitles = map[lifecycle.Status]string{
+	lifecycle.Pending:       "На проверке",
+	lifecycle.NeedsRevision: "На доработке",
+	lifecycle.OnHold:        "Отложены",
+	lifecycle.Approved:      "Не оплачены",
+}
+
+// Фильтры переключаются по кругу в этом порядке
+var queueUserStatuses = []string{queueAll, "student", "employee", "graduate"}
+
+var queueUserStatusTitles = map[string]string{
+	queueAll:   "все",
+	"student":  "студенты",
+	"employee": "сотрудники",
+	"graduate": "выпускники",
+}
+
+var queuePeriods = []string{queueAll, "1d", "7d", "30d"}
+
+var queuePeriodTitles = map[string]string{
+	queueAll: "всё время",
+	"1d":     "за сутки",
+	"7d":     "за неделю",
+	"30d":    "за месяц",
+}
+
+var queuePeriodDays = map[string]int{
+	"1d":  1,
+	"7d":  7,
+	"30d": 30,
+}
+
+// queueView — состояние списка, целиком хранится в данных кнопок:
+// queue:l:<status>:<user_status>:<period>:<page>
+type queueView struct {
+	status     lifecycle.Status
+	userStatus string
+	period     string
+	page       int
+}
+
+func (v queueView) data() string {
+	return fmt.Sprintf("%s:l:%s:%s:%s:%d", queueCallbackPrefix, v.status, v.userStatus, v.period, v.page)
+}
+
+func (v queueView) filter() db.RequestFilter {
+	filter := db.RequestFilter{Status: v.status}
+
+	if v.userStatus != queueAll {
+		filter.UserStatus = v.userStatus
+	}
+
+	if days, ok := queuePeriodDays[v.period]; ok {
+		filter.CreatedSince = time.Now().AddDate(0, 0, -days)
+	}
+
+	return filter
+}
+
+func parseQueueView(parts []string) (queueView, bool) {
+	if len(parts) != 6 {
+		return queueView{}, false
+	}
+
+	view := queueView{
+		status:     lifecycle.Status(parts[2]),
+		userStatus: parts[3],
+		period:     parts[4],
+	}
+
+	page, err := strconv.Atoi(parts[5])
+	if err != nil || page < 0 {
+		return queueView{}, false
+	}
+	view.page = page
+
+	if queueStatusTitles[view.status] == "" || queueUserStatusTitles[view.userStatus] == "" || queuePeriodTitles[view.period] == "" {
+		return queueView{}, false
+	}
+
+	return view, true
+}
+
+// Следующее значение фильтра по кругу
+func nextOption(options []string, current string) string {
+	for i, option := range options {
+		if option == current {
+			return options[(i+1)%len(options)]
+		}
+	}
+	return options[0]
+}
+
+// Номер заявки из текста вида #123
+func parseRequestNumber(text string) (int64, bool) {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "#") {
+		return 0, false
+	}
+
+	id, err := strconv.ParseInt(text[1:], 10, 64)
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+
+	return id, true
+}
+
+func (b *BotService) handleQueue(chatID int64) {
+	b.showQueue(chatID, nil, queueView{status: lifecycle.Pending, userStatus: queueAll, period: queueAll})
+}
+
+// Обработка нажатий в очереди: queue:l:... — список, queue:o:<id> — открыть заявку
+func (b *BotService) handleQueueCallback(query *tgbotapi.CallbackQuery) {
+	b.botAPI.Request(tgbotapi.NewCallback(query.ID, ""))
+
+	if query.Message == nil {
+		return
+	}
+
+	chatID := query.Message.Chat.ID
+	parts := strings.Split(query.Data, ":")
+
+	if len(parts) == 3 && parts[1] == "o" {
+		requestID, err := strconv.ParseInt(parts[2], 10, 64)
+		if err != nil {
+			log.Printf("bad queue callback %q", query.Data)
+			return
+		}
+		b.openRequest(chatID, requestID)
+		return
+	}
+
+	view, ok := parseQueueView(parts)
+	if len(parts) < 2 || parts[1] != "l" || !ok {
+		log.Printf("bad queue callback %q", query.Data)
+		return
+	}
+
+	b.showQueue(chatID, query.Message, view)
+}
+
+func (b *BotService) showQueue(chatID int64, current *tgbotapi.Message, view queueView) {
+	counts, err := b.registrationRepo.CountByStatus()
+	if err != nil {
+		log.Printf("Error counting requests: %v\n", err)
+		msg := tgbotapi.NewMessage(chatID, "Ошибка при получении заявок.")
+		b.botAPI.Send(msg)
+		return
+	}
+
+	filter := view.filter()
+
+	total, err := b.registrationRepo.Count(filter)
+	if err != nil {
+		log.Printf("Error counting requests: %v\n", err)
+		msg := tgbotapi.NewMessage(chatID, "Ошибка при получении заявок.")
+		b.botAPI.Send(msg)
+		return
+	}
+
+	// Если заявки разобрали, пока список был открыт, возвращаемся на последнюю страницу
+	if pages := (total + queuePageSize - 1) / queuePageSize; view.page >= pages && pages > 0 {
+		view.page = pages - 1
+	}
+
+	requests, err := b.registrationRepo.List(filter, db.Page{Limit: queuePageSize, Offset: view.page * queuePageSize})
+	if err != nil {
+		log.Printf("Error loading requests: %v\n", err)
+		msg := tgbotapi.NewMessage(chatID, "Ошибка при получении заявок.")
+		b.botAPI.Send(msg)
+		return
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+
+	// Разделы по статусу с количеством заявок, по два в ряд
+	var statusRow []tgbotapi.InlineKeyboardButton
+	for _, status := range queueStatuses {
+		title := fmt.Sprintf("%s (%d)", queueStatusTitles[status], counts[status])
+		if status == view.status {
+			title = "• " + title
+		}
+
+		target := queueView{status: status, userStatus: view.userStatus, period: view.period}
+		statusRow = append(statusRow, tgbotapi.NewInlineKeyboardButtonData(title, target.data()))
+
+		if len(statusRow) == 2 {
+			rows = append(rows, statusRow)
+			statusRow = nil
+		}
+	}
+
+	nextUserStatus := view
+	nextUserStatus.userStatus = nextOption(queueUserStatuses, view.userStatus)
+	nextUserStatus.page = 0
+
+	nextPeriod := view
+	nextPeriod.period = nextOption(queuePeriods, view.period)
+	nextPeriod.page = 0
+
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("Кто: "+queueUserStatusTitles[view.userStatus], nextUserStatus.data()),
+		tgbotapi.NewInlineKeyboardButtonData("Когда: "+queuePeriodTitles[view.period], nextPeriod.data()),
+	))
+
+	for _, req := range requests {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("#%d %s %s, %s", req.ID, req.FirstName, req.LastName, req.CreatedAt.Format("02.01")),
+				fmt.Sprintf("%s:o:%d", queueCallbackPrefix, req.ID),
+			),
+		))
+	}
+
+	var nav []tgbotapi.InlineKeyboardButton
+	if view.page > 0 {
+		prev := view
+		prev.page--
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData("‹ Назад", prev.data()))
+	}
+	if (view.page+1)*queuePageSize < total {
+		next := view
+		next.page++
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData("Вперёд ›", next.data()))
+	}
+	if len(nav) > 0 {
+		rows = append(rows, nav)
+	}
+
+	text := fmt.Sprintf("<b>%s</b>: %d", html.EscapeString(queueStatusTitles[view.status]), total)
+	if total > queuePageSize {
+		text += fmt.Sprintf(", страница %d из %d", view.page+1, (total+queuePageSize-1)/queuePageSize)
+	}
+	text += "\nОткройте заявку кнопкой или отправьте её номер, например #123"
+
+	b.showInlineView(chatID, current, text, tgbotapi.NewInlineKeyboardMarkup(rows...))
+}
@@ -0,0 +1,60 @@
+package adminbot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gratefultolord/ac_signup_bot/internal/lifecycle"
+)
+
+func TestParseQueueView(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want queueView
+		ok   bool
+	}{
+		{
+			name: "defaults",
+			data: "queue:l:pending:all:all:0",
+			want: queueView{status: lifecycle.Pending, userStatus: queueAll, period: queueAll},
+			ok:   true,
+		},
+		{
+			name: "all filters",
+			data: "queue:l:approved:graduate:30d:3",
+			want: queueView{status: lifecycle.Approved, userStatus: "graduate", period: "30d", page: 3},
+			ok:   true,
+		},
+		{name: "status outside queue", data: "queue:l:paid:all:all:0"},
+		{name: "unknown status", data: "queue:l:archived:all:all:0"},
+		{name: "unknown user status", data: "queue:l:pending:teacher:all:0"},
+		{name: "unknown period", data: "queue:l:pending:all:90d:0"},
+		{name: "negative page", data: "queue:l:pending:all:all:-1"},
+		{name: "page is not a number", data: "queue:l:pending:all:all:x"},
+		{name: "too few parts", data: "queue:l:pending:all:all"},
+		{name: "too many parts", data: "queue:l:pending:all:all:0:1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseQueueView(strings.Split(tt.data, ":"))
+			if ok != tt.ok {
+				t.Fatalf("parseQueueView(%q) ok = %v, want %v", tt.data, ok, tt.ok)
+			}
+
+			if ok && got != tt.want {
+				t.Fatalf("parseQueueView(%q) = %+v, want %+v", tt.data, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueueViewDataRoundTrip(t *testing.T) {
+	view := queueView{status: lifecycle.OnHold, userStatus: "student", period: "7d", page: 2}
+
+	got, ok := parseQueueView(strings.Split(view.data(), ":"))
+	if !ok || got != view {
+		t.Fatalf("parseQueueView(%q) = %+v, %v, want %+v", view.data(), got, ok, view)
+	}
+}
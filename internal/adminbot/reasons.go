@@ -0,0 +1,355 @@
+package adminbot
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/gratefultolord/ac_signup_bot/internal/db"
+	"github.com/gratefultolord/ac_signup_bot/internal/lifecycle"
+)
+
+const (
+	reasonsCallbackPrefix = "reasons"
+
+	// Ответ на предложение дополнить причину из шаблона
+	reasonWithoutComment = "Без пояснения"
+
+	reasonButtonLimit = 40
+)
+
+// Порядок разделов в списке шаблонов
+var reasonKinds = []lifecycle.Status{lifecycle.Rejected, lifecycle.NeedsRevision}
+
+var reasonKindTitles = map[lifecycle.Status]string{
+	lifecycle.Rejected:      "Отклонение",
+	lifecycle.NeedsRevision: "Доработка",
+}
+
+// Шаги ввода причины и вид шаблонов, которые в них предлагаются
+var reasonSteps = map[string]lifecycle.Status{
+	StateEnteringRejectReason:   lifecycle.Rejected,
+	StateEnteringRevisionReason: lifecycle.NeedsRevision,
+}
+
+func ReasonCommentMenu() tgbotapi.ReplyKeyboardMarkup {
+	return tgbotapi.NewReplyKeyboard(
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton(reasonWithoutComment),
+		),
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton("Отмена"),
+		),
+	)
+}
+
+func reasonsData(action string, id int64) string {
+	return fmt.Sprintf("%s:%s:%d", reasonsCallbackPrefix, action, id)
+}
+
+// Предложить готовые причины вдобавок к вводу своей. Без шаблонов админ просто вводит текст
+func (b *BotService) showReasonPicker(chatID int64, kind lifecycle.Status) {
+	templates, err := b.reasonRepo.List(kind)
+	if err != nil {
+		log.Printf("Error loading reason templates: %v\n", err)
+		return
+	}
+
+	if len(templates) == 0 {
+		return
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, t := range templates {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(truncateText(t.Text, reasonButtonLimit), reasonsData("pick", t.ID)),
+		))
+	}
+
+	msg := tgbotapi.NewMessage(chatID, "Или выберите готовую причину:")
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	b.botAPI.Send(msg)
+}
+
+func (b *BotService) handleReasonTemplates(chatID int64) {
+	b.showReasonTemplates(chatID, nil)
+}
+
+// Обработка нажатий в разделе «Шаблоны причин»: reasons — список,
+// reasons:<action>:<id> — действие над шаблоном, reasons:new:<kind> — новый шаблон,
+// reasons:pick:<id> — выбор причины при решении по заявке
+func (b *BotService) handleReasonsCallback(query *tgbotapi.CallbackQuery) {
+	if query.Message == nil {
+		b.botAPI.Request(tgbotapi.NewCallback(query.ID, ""))
+		return
+	}
+
+	chatID := query.Message.Chat.ID
+	parts := strings.Split(query.Data, ":")
+
+	if len(parts) == 3 && parts[1] == "pick" {
+		b.pickReasonTemplate(query, parts[2])
+		return
+	}
+
+	b.botAPI.Request(tgbotapi.NewCallback(query.ID, ""))
+
+	// Нажатие кнопки прерывает незавершённый ввод шаблона
+	if b.adminStates[chatID].Step == StateEnteringReasonTemplate {
+		b.adminStates[chatID] = &AdminState{Step: StateMainMenu}
+	}
+
+	if len(parts) == 1 {
+		b.showReasonTemplates(chatID, query.Message)
+		return
+	}
+
+	if len(parts) != 3 {
+		log.Printf("bad reasons callback %q", query.Data)
+		return
+	}
+
+	if parts[1] == "new" {
+		kind := lifecycle.Status(parts[2])
+		if _, ok := reasonKindTitles[kind]; !ok {
+			log.Printf("bad reasons callback %q", query.Data)
+			return
+		}
+
+		b.startReasonTemplateInput(chatID, &AdminState{TemplateKind: kind},
+			fmt.Sprintf("Введите текст новой причины (раздел «%s», до %d символов)", reasonKindTitles[kind], db.ReasonTemplateMaxLength))
+		return
+	}
+
+	templateID, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		log.Printf("bad reasons callback %q", query.Data)
+		return
+	}
+
+	switch parts[1] {
+	case "t":
+		b.showReasonTemplate(chatID, query.Message, templateID)
+
+	case "edit":
+		b.startReasonTemplateInput(chatID, &AdminState{TemplateID: templateID},
+			fmt.Sprintf("Введите новый текст причины (до %d символов)", db.ReasonTemplateMaxLength))
+
+	case "del":
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("Да, удалить", reasonsData("delok", templateID)),
+				tgbotapi.NewInlineKeyboardButtonData("Отмена", reasonsData("t", templateID)),
+			),
+		)
+		b.showInlineView(chatID, query.Message, "Удалить шаблон? В истории заявок причина сохранится", keyboard)
+
+	case "delok":
+		if err := b.reasonRepo.Delete(templateID); err != nil {
+			log.Printf("Error deleting reason template: %v\n", err)
+			msg := tgbotapi.NewMessage(chatID, "Не удалось удалить шаблон")
+			b.botAPI.Send(msg)
+			return
+		}
+
+		b.audit(chatID, db.AuditReasonDelete, auditTargetReason, templateID, "")
+
+		b.showReasonTemplates(chatID, query.Message)
+
+	default:
+		log.Printf("bad reasons callback %q", query.Data)
+	}
+}
+
+func (b *BotService) showReasonTemplates(chatID int64, current *tgbotapi.Message) {
+	var sb strings.Builder
+	sb.WriteString("Шаблоны причин. Использования считаются по решениям, принятым с шаблоном")
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+
+	for _, kind := range reasonKinds {
+		templates, err := b.reasonRepo.List(kind)
+		if err != nil {
+			log.Printf("Error loading reason templates: %v\n", err)
+			msg := tgbotapi.NewMessage(chatID, "Ошибка при загрузке шаблонов")
+			b.botAPI.Send(msg)
+			return
+		}
+
+		fmt.Fprintf(&sb, "\n\n<b>%s</b>", reasonKindTitles[kind])
+
+		if len(templates) == 0 {
+			sb.WriteString("\nШаблонов нет")
+		}
+
+		for i, t := range templates {
+			fmt.Fprintf(&sb, "\n%d. %s — %s", i+1, html.EscapeString(t.Text), reasonUsage(t))
+
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(
+					fmt.Sprintf("%s %d. %s", reasonKindTitles[kind], i+1, truncateText(t.Text, reasonButtonLimit)),
+					reasonsData("t", t.ID),
+				),
+			))
+		}
+	}
+
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("+ Отклонение", fmt.Sprintf("%s:new:%s", reasonsCallbackPrefix, lifecycle.Rejected)),
+		tgbotapi.NewInlineKeyboardButtonData("+ Доработка", fmt.Sprintf("%s:new:%s", reasonsCallbackPrefix, lifecycle.NeedsRevision)),
+	))
+
+	b.showInlineView(chatID, current, sb.String(), tgbotapi.NewInlineKeyboardMarkup(rows...))
+}
+
+func (b *BotService) showReasonTemplate(chatID int64, current *tgbotapi.Message, templateID int64) {
+	template, err := b.reasonRepo.GetByID(templateID)
+	if errors.Is(err, sql.ErrNoRows) {
+		b.showReasonTemplates(chatID, current)
+		return
+	}
+	if err != nil {
+		log.Printf("Error loading reason template: %v\n", err)
+		msg := tgbotapi.NewMessage(chatID, "Ошибка при загрузке шаблона")
+		b.botAPI.Send(msg)
+		return
+	}
+
+	usage := "нет данных"
+
+	templates, err := b.reasonRepo.List(template.Kind)
+	if err != nil {
+		log.Printf("Error loading reason template usage: %v\n", err)
+	}
+
+	for _, t := range templates {
+		if t.ID == templateID {
+			usage = reasonUsage(t)
+		}
+	}
+
+	text := fmt.Sprintf("<b>%s</b>\n%s\n\n%s", reasonKindTitles[template.Kind], html.EscapeString(template.Text), usage)
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Изменить текст", reasonsData("edit", templateID)),
+			tgbotapi.NewInlineKeyboardButtonData("Удалить", reasonsData("del", templateID)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("« Все шаблоны", reasonsCallbackPrefix),
+		),
+	)
+
+	b.showInlineView(chatID, current, text, keyboard)
+}
+
+func reasonUsage(t db.ReasonTemplateUsage) string {
+	if t.LastUsedAt == nil {
+		return "не использовался"
+	}
+
+	return fmt.Sprintf("использований: %d, за 30 дней: %d, последнее %s",
+		t.Uses, t.RecentUses, t.LastUsedAt.Format("02.01.2006"))
+}
+
+func (b *BotService) startReasonTemplateInput(chatID int64, state *AdminState, prompt string) {
+	state.Step = StateEnteringReasonTemplate
+	b.adminStates[chatID] = state
+
+	msg := tgbotapi.NewMessage(chatID, prompt)
+	msg.ReplyMarkup = CancelMenu()
+	b.botAPI.Send(msg)
+}
+
+func (b *BotService) handleReasonTemplateInput(chatID int64, text string) {
+	state := b.adminStates[chatID]
+
+	if text == "Отмена" {
+		b.adminStates[chatID] = &AdminState{Step: StateMainMenu}
+		b.restoreMainMenu(chatID, "Отменено")
+		b.showReasonTemplates(chatID, nil)
+		return
+	}
+
+	template := &db.ReasonTemplate{Kind: state.TemplateKind, Text: strings.TrimSpace(text)}
+
+	action := db.AuditReasonUpdate
+
+	var err error
+	if state.TemplateID == 0 {
+		action = db.AuditReasonCreate
+		err = b.reasonRepo.Create(template)
+	} else {
+		template, err = b.reasonRepo.GetByID(state.TemplateID)
+		if err == nil {
+			template.Text = strings.TrimSpace(text)
+			err = b.reasonRepo.Update(template)
+		}
+	}
+
+	if errors.Is(err, db.ErrValidation) {
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("Текст причины должен быть непустым и не длиннее %d символов. Введите еще раз", db.ReasonTemplateMaxLength))
+		msg.ReplyMarkup = CancelMenu()
+		b.botAPI.Send(msg)
+		return
+	}
+	if err != nil {
+		log.Printf("Error saving reason template: %v\n", err)
+		b.adminStates[chatID] = &AdminState{Step: StateMainMenu}
+		b.restoreMainMenu(chatID, "Не удалось сохранить шаблон")
+		return
+	}
+
+	b.audit(chatID, action, auditTargetReason, template.ID, template.Text)
+
+	b.adminStates[chatID] = &AdminState{Step: StateMainMenu}
+	b.restoreMainMenu(chatID, "Шаблон сохранён")
+	b.showReasonTemplate(chatID, nil, template.ID)
+}
+
+// Выбор готовой причины на шаге ввода причины отклонения или доработки.
+// После выбора админ может дополнить причину своим текстом
+func (b *BotService) pickReasonTemplate(query *tgbotapi.CallbackQuery, data string) {
+	chatID := query.Message.Chat.ID
+	state := b.adminStates[chatID]
+
+	templateID, err := strconv.ParseInt(data, 10, 64)
+	if err != nil {
+		b.botAPI.Request(tgbotapi.NewCallback(query.ID, ""))
+		log.Printf("bad reasons callback %q", query.Data)
+		return
+	}
+
+	kind, ok := reasonSteps[state.Step]
+	if !ok {
+		b.botAPI.Request(tgbotapi.NewCallback(query.ID, "Сначала выберите действие в карточке заявки"))
+		return
+	}
+
+	template, err := b.reasonRepo.GetByID(templateID)
+	if err != nil || template.Kind != kind {
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			log.Printf("Error loading reason template: %v\n", err)
+		}
+		b.botAPI.Request(tgbotapi.NewCallback(query.ID, "Этот шаблон сейчас выбрать нельзя"))
+		return
+	}
+
+	state.TemplateID = template.ID
+	state.TemplateText = template.Text
+
+	b.botAPI.Request(tgbotapi.NewCallback(query.ID, ""))
+
+	edit := tgbotapi.NewEditMessageText(chatID, query.Message.MessageID, "Причина: "+template.Text)
+	b.botAPI.Request(edit)
+
+	msg := tgbotapi.NewMessage(chatID, "Добавьте пояснение для пользователя или нажмите «"+reasonWithoutComment+"»")
+	msg.ReplyMarkup = ReasonCommentMenu()
+	b.botAPI.Send(msg)
+}
@@ -0,0 +1,524 @@
+package adminbot
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/AlekSi/pointer"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/gratefultolord/ac_signup_bot/internal/db"
+	"github.com/gratefultolord/ac_signup_bot/internal/events"
+	"github.com/gratefultolord/ac_signup_bot/internal/lifecycle"
+)
+
+// Кнопки карточки заявки: review:<действие>:<id> и review:next.
+// Номер заявки в данных кнопки позволяет действовать по любой карточке в истории чата
+const reviewCallbackPrefix = "review"
+
+var decisionResults = map[lifecycle.Status]string{
+	lifecycle.Approved:      "Заявка одобрена",
+	lifecycle.Rejected:      "Заявка отклонена",
+	lifecycle.NeedsRevision: "Заявка отправлена на доработку",
+}
+
+var decisionEvents = map[lifecycle.Status]events.Type{
+	lifecycle.Approved:      events.RequestApproved,
+	lifecycle.Rejected:      events.RequestRejected,
+	lifecycle.NeedsRevision: events.RevisionRequested,
+}
+
+var decisionAudit = map[lifecycle.Status]string{
+	lifecycle.Approved:      db.AuditRequestApprove,
+	lifecycle.Rejected:      db.AuditRequestReject,
+	lifecycle.NeedsRevision: db.AuditRequestRevise,
+}
+
+var outcomeIcons = map[lifecycle.Status]string{
+	lifecycle.Approved:      "✅",
+	lifecycle.Rejected:      "❌",
+	lifecycle.NeedsRevision: "✏️",
+	lifecycle.OnHold:        "⏸",
+	lifecycle.Paid:          "💳",
+}
+
+func reviewData(action string, requestID int64) string {
+	return fmt.Sprintf("%s:%s:%d", reviewCallbackPrefix, action, requestID)
+}
+
+func (b *BotService) showRequest(chatID int64, req *db.RegistrationRequest) {
+	b.adminStates[chatID] = &AdminState{
+		Step:      StateMainMenu,
+		RequestID: req.ID,
+	}
+
+	text, keyboard := b.requestCardView(req)
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = keyboard
+	b.botAPI.Send(msg)
+
+	// Документ содержит персональные данные, поэтому каждый показ попадает в журнал
+	if req.DocumentPath != "" {
+		doc := tgbotapi.NewDocument(chatID, tgbotapi.FilePath(req.DocumentPath))
+		if _, err := b.botAPI.Send(doc); err == nil {
+			b.audit(chatID, db.AuditDocumentView, auditTargetRequest, req.ID, "")
+		}
+	}
+}
+
+// Перерисовать карточку на месте, чтобы она показывала текущий статус и кто его поставил
+func (b *BotService) refreshCard(chatID int64, messageID int, requestID int64) {
+	if messageID == 0 {
+		return
+	}
+
+	req, err := b.registrationRepo.GetByID(requestID)
+	if err != nil {
+		log.Printf("Error loading request %d: %v\n", requestID, err)
+		return
+	}
+
+	text, keyboard := b.requestCardView(req)
+
+	edit := tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID, text, keyboard)
+	if _, err := b.botAPI.Request(edit); err != nil {
+		log.Printf("Error updating request card: %v\n", err)
+	}
+}
+
+// Текст карточки и кнопки, доступные в текущем статусе заявки
+func (b *BotService) requestCardView(req *db.RegistrationRequest) (string, tgbotapi.InlineKeyboardMarkup) {
+	info := fmt.Sprintf(
+		"Заявка #%d\nИмя: %s\nФамилия:%s\nДата рождения: %s\nСтатус: %s\nТелефон: %s",
+		req.ID, req.FirstName, req.LastName, req.BirthDate.Format("02.01.2006"), req.UserStatus, req.PhoneNumber,
+	)
+
+	if req.RevisionCount > 0 {
+		info += fmt.Sprintf("\nПосле доработки: %d раз", req.RevisionCount)
+	}
+
+	history, err := b.registrationRepo.GetEvents(req.ID)
+	if err != nil {
+		log.Printf("Error loading request history: %v\n", err)
+	}
+
+	if req.Status != lifecycle.Pending {
+		info = b.requestOutcome(req, history) + "\n\n" + info
+	}
+
+	if req.Status == lifecycle.OnHold {
+		info += holdSummary(req)
+	}
+
+	info += b.requestNotes(req.ID) + requestTimeline(history)
+
+	note := tgbotapi.NewInlineKeyboardButtonData("📝 Заметка", reviewData("note", req.ID))
+	next := tgbotapi.NewInlineKeyboardButtonData("Следующая ›", reviewCallbackPrefix+":next")
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+
+	switch req.Status {
+	case lifecycle.Pending:
+		rows = append(rows,
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("✅ Одобрить", reviewData("approve", req.ID)),
+				tgbotapi.NewInlineKeyboardButtonData("❌ Отклонить", reviewData("reject", req.ID)),
+			),
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("✏️ На доработку", reviewData("revise", req.ID)),
+				tgbotapi.NewInlineKeyboardButtonData("⏸ Отложить", reviewData("hold", req.ID)),
+			),
+		)
+	case lifecycle.OnHold:
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Вернуть в работу", reviewData("resume", req.ID)),
+		))
+	}
+
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(note, next))
+
+	return info, tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// Итог рассмотрения: статус и последнее изменение из истории
+func (b *BotService) requestOutcome(req *db.RegistrationRequest, history []db.RequestEvent) string {
+	outcome := fmt.Sprintf("%s %s", outcomeIcons[req.Status], strings.ToUpper(requestStatusTitle(req.Status)))
+
+	if len(history) > 0 {
+		last := history[len(history)-1]
+		outcome += fmt.Sprintf(" — %s, %s", eventActor(last), last.CreatedAt.Format("02.01.2006 15:04"))
+	}
+
+	return strings.TrimSpace(outcome)
+}
+
+// Заметки админов для карточки заявки; при ошибке карточка показывается без них
+func (b *BotService) requestNotes(requestID int64) string {
+	notes, err := b.registrationRepo.GetNotes(requestID)
+	if err != nil {
+		log.Printf("Error loading request notes: %v\n", err)
+		return ""
+	}
+
+	if len(notes) == 0 {
+		return ""
+	}
+
+	if len(notes) > requestNotesLimit {
+		notes = notes[len(notes)-requestNotesLimit:]
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\n\nЗаметки админов:")
+
+	for _, n := range notes {
+		fmt.Fprintf(&sb, "\n%s админ %d: %s", n.CreatedAt.Format("02.01.2006 15:04"), n.AdminChatID, truncateText(n.Text, requestNoteLimit))
+	}
+
+	return sb.String()
+}
+
+// История смены статусов для карточки заявки
+func requestTimeline(history []db.RequestEvent) string {
+	if len(history) == 0 {
+		return ""
+	}
+
+	if len(history) > requestTimelineLimit {
+		history = history[len(history)-requestTimelineLimit:]
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\n\nИстория:")
+
+	for _, e := range history {
+		transition := requestStatusTitle(e.NewStatus)
+		if e.OldStatus != nil {
+			transition = requestStatusTitle(*e.OldStatus) + " → " + transition
+		}
+
+		fmt.Fprintf(&sb, "\n%s %s (%s)", e.CreatedAt.Format("02.01.2006 15:04"), transition, eventActor(e))
+		if e.Reason != nil && *e.Reason != "" {
+			fmt.Fprintf(&sb, ": %s", truncateText(*e.Reason, requestReasonLimit))
+		}
+	}
+
+	return sb.String()
+}
+
+func eventActor(e db.RequestEvent) string {
+	switch e.ActorType {
+	case db.ActorAdmin:
+		return fmt.Sprintf("админ %d", e.ActorChatID)
+	case db.ActorSystem:
+		return "напоминание"
+	}
+	return "пользователь"
+}
+
+// Закрепить за админом конкретную заявку; прежняя открытая им заявка освобождается
+func (b *BotService) claimRequest(chatID, requestID int64) (*db.RegistrationRequest, error) {
+	admin, err := b.adminRepo.GetByChatID(chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	if prev := b.adminStates[chatID].RequestID; prev != 0 && prev != requestID {
+		b.releaseRequest(chatID, prev)
+	}
+
+	return b.registrationRepo.Claim(requestID, admin.ID, b.claimTTL)
+}
+
+func (b *BotService) releaseRequest(chatID, requestID int64) {
+	admin, err := b.adminRepo.GetByChatID(chatID)
+	if err == nil {
+		err = b.registrationRepo.Release(requestID, admin.ID)
+	}
+
+	if err != nil {
+		log.Printf("Error releasing request %d: %v\n", requestID, err)
+	}
+}
+
+// Открыть заявку по номеру. Заявку на проверке закрепляем за админом,
+// остальные показываем с итогом рассмотрения
+func (b *BotService) openRequest(chatID, requestID int64) {
+	req, err := b.registrationRepo.GetByID(requestID)
+	if errors.Is(err, sql.ErrNoRows) {
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("Заявка #%d не найдена", requestID))
+		b.botAPI.Send(msg)
+		return
+	}
+	if err != nil {
+		log.Printf("Error loading request %d: %v\n", requestID, err)
+		msg := tgbotapi.NewMessage(chatID, "Ошибка при получении заявки.")
+		b.botAPI.Send(msg)
+		return
+	}
+
+	if req.Status == lifecycle.Pending {
+		claimed, err := b.claimRequest(chatID, requestID)
+		switch {
+		case errors.Is(err, db.ErrRequestNotClaimed):
+			msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("Заявку #%d сейчас проверяет другой админ", requestID))
+			b.botAPI.Send(msg)
+		case err != nil:
+			log.Printf("Error claiming request %d: %v\n", requestID, err)
+		default:
+			req = claimed
+		}
+	}
+
+	b.showRequest(chatID, req)
+}
+
+func (b *BotService) handleReviewCallback(query *tgbotapi.CallbackQuery) {
+	chatID := query.From.ID
+
+	// Нерешённую заявку отпускаем, иначе ClaimNextPending снова вернул бы её первой
+	if query.Data == reviewCallbackPrefix+":next" {
+		b.botAPI.Request(tgbotapi.NewCallback(query.ID, ""))
+
+		current := b.adminStates[chatID].RequestID
+		if current != 0 {
+			b.releaseRequest(chatID, current)
+		}
+
+		b.showNextRequest(chatID, current)
+		return
+	}
+
+	parts := strings.Split(query.Data, ":")
+	if len(parts) != 3 {
+		b.botAPI.Request(tgbotapi.NewCallback(query.ID, ""))
+		log.Printf("bad review callback %q", query.Data)
+		return
+	}
+
+	requestID, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		b.botAPI.Request(tgbotapi.NewCallback(query.ID, ""))
+		log.Printf("bad review callback %q", query.Data)
+		return
+	}
+
+	var messageID int
+	if query.Message != nil {
+		messageID = query.Message.MessageID
+	}
+
+	switch parts[1] {
+	case "approve":
+		if _, err := b.claimRequest(chatID, requestID); err != nil {
+			b.answerReviewError(query, requestID, err)
+			return
+		}
+
+		if err := b.decide(chatID, requestID, lifecycle.Approved, nil, nil); err != nil {
+			b.answerReviewError(query, requestID, err)
+			return
+		}
+
+		b.botAPI.Request(tgbotapi.NewCallback(query.ID, decisionResults[lifecycle.Approved]))
+		b.refreshCard(chatID, messageID, requestID)
+		b.handleCheckRequests(chatID)
+
+	case "reject", "revise", "hold":
+		if _, err := b.claimRequest(chatID, requestID); err != nil {
+			b.answerReviewError(query, requestID, err)
+			return
+		}
+
+		step, prompt := StateEnteringRejectReason, "Введите причину отклонения"
+		switch parts[1] {
+		case "revise":
+			step, prompt = StateEnteringRevisionReason, "Введите причину отправки на доработку"
+		case "hold":
+			step, prompt = StateEnteringHoldNote, "Введите заметку для админов: что нужно уточнить. Пользователь её не увидит"
+		}
+
+		b.botAPI.Request(tgbotapi.NewCallback(query.ID, ""))
+		b.startCardInput(chatID, requestID, messageID, step, prompt)
+
+		if kind, ok := reasonSteps[step]; ok {
+			b.showReasonPicker(chatID, kind)
+		}
+
+	case "note":
+		b.botAPI.Request(tgbotapi.NewCallback(query.ID, ""))
+		b.startCardInput(chatID, requestID, messageID, StateEnteringRequestNote, "Введите заметку. Её увидят только админы")
+
+	case "resume":
+		if prev := b.adminStates[chatID].RequestID; prev != 0 && prev != requestID {
+			b.releaseRequest(chatID, prev)
+		}
+
+		admin, err := b.adminRepo.GetByChatID(chatID)
+		if err == nil {
+			_, err = b.registrationRepo.Resume(requestID, admin.ID)
+		}
+		if err != nil {
+			b.answerReviewError(query, requestID, err)
+			return
+		}
+
+		b.audit(chatID, db.AuditRequestResume, auditTargetRequest, requestID, "")
+
+		b.adminStates[chatID].RequestID = requestID
+		b.botAPI.Request(tgbotapi.NewCallback(query.ID, "Заявка снова на проверке"))
+		b.refreshCard(chatID, messageID, requestID)
+
+	default:
+		b.botAPI.Request(tgbotapi.NewCallback(query.ID, ""))
+		log.Printf("bad review callback %q", query.Data)
+	}
+}
+
+// Ввод текста к заявке по кнопке карточки; после ввода карточка обновится на месте
+func (b *BotService) startCardInput(chatID, requestID int64, messageID int, step, prompt string) {
+	b.adminStates[chatID] = &AdminState{
+		Step:          step,
+		RequestID:     requestID,
+		CardMessageID: messageID,
+	}
+
+	msg := tgbotapi.NewMessage(chatID, prompt)
+	msg.ReplyMarkup = CancelMenu()
+	b.botAPI.Send(msg)
+}
+
+// Завершить ввод: вернуть главное меню и обновить карточку
+func (b *BotService) finishCardInput(chatID int64, text string) {
+	state := b.adminStates[chatID]
+	b.adminStates[chatID] = &AdminState{Step: StateMainMenu, RequestID: state.RequestID}
+
+	b.restoreMainMenu(chatID, text)
+	b.refreshCard(chatID, state.CardMessageID, state.RequestID)
+}
+
+func (b *BotService) handleRejectReason(chatID int64, text string) {
+	b.handleDecisionReason(chatID, text, lifecycle.Rejected)
+}
+
+func (b *BotService) handleRevisionReason(chatID int64, text string) {
+	b.handleDecisionReason(chatID, text, lifecycle.NeedsRevision)
+}
+
+func (b *BotService) handleDecisionReason(chatID int64, text string, status lifecycle.Status) {
+	if text == "Отмена" {
+		b.finishCardInput(chatID, "Отменено")
+		return
+	}
+
+	state := b.adminStates[chatID]
+
+	if strings.TrimSpace(text) == "" {
+		msg := tgbotapi.NewMessage(chatID, "Причина не может быть пустой. Введите текст")
+		msg.ReplyMarkup = CancelMenu()
+		if state.TemplateID != 0 {
+			msg.ReplyMarkup = ReasonCommentMenu()
+		}
+		b.botAPI.Send(msg)
+		return
+	}
+
+	requestID := state.RequestID
+
+	// Причина из шаблона дополняется пояснением админа, если оно есть
+	reason := text
+	var templateID *int64
+	if state.TemplateID != 0 {
+		reason = state.TemplateText
+		if text != reasonWithoutComment {
+			reason += "\n" + strings.TrimSpace(text)
+		}
+		templateID = pointer.ToInt64(state.TemplateID)
+	}
+
+	if err := b.decide(chatID, requestID, status, &reason, templateID); err != nil {
+		b.finishCardInput(chatID, b.reviewErrorText(requestID, err))
+		return
+	}
+
+	b.finishCardInput(chatID, decisionResults[status])
+	b.handleCheckRequests(chatID)
+}
+
+func (b *BotService) handleRequestNote(chatID int64, text string) {
+	if text == "Отмена" {
+		b.finishCardInput(chatID, "Отменено")
+		return
+	}
+
+	text = strings.TrimSpace(text)
+	if text == "" || len([]rune(text)) > requestNoteMaxLength {
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("Заметка должна быть непустой и не длиннее %d символов. Введите еще раз", requestNoteMaxLength))
+		msg.ReplyMarkup = CancelMenu()
+		b.botAPI.Send(msg)
+		return
+	}
+
+	requestID := b.adminStates[chatID].RequestID
+
+	if err := b.registrationRepo.AddNote(requestID, chatID, text); err != nil {
+		log.Printf("Error saving request note: %v\n", err)
+		msg := tgbotapi.NewMessage(chatID, "Ошибка при сохранении заметки. Попробуйте еще раз")
+		msg.ReplyMarkup = CancelMenu()
+		b.botAPI.Send(msg)
+		return
+	}
+
+	b.audit(chatID, db.AuditRequestNote, auditTargetRequest, requestID, "")
+
+	b.finishCardInput(chatID, "Заметка сохранена")
+}
+
+// Сохранить решение по заявке и сообщить о нём боту регистрации
+func (b *BotService) decide(chatID, requestID int64, status lifecycle.Status, reason *string, templateID *int64) error {
+	admin, err := b.adminRepo.GetByChatID(chatID)
+	if err != nil {
+		return err
+	}
+
+	if err := b.registrationRepo.UpdateStatus(requestID, admin.ID, status, reason, templateID); err != nil {
+		return err
+	}
+
+	b.publish(events.Event{Type: decisionEvents[status], RequestID: requestID})
+	b.audit(chatID, decisionAudit[status], auditTargetRequest, requestID, pointer.GetString(reason))
+
+	return nil
+}
+
+func (b *BotService) answerReviewError(query *tgbotapi.CallbackQuery, requestID int64, err error) {
+	b.botAPI.Request(tgbotapi.NewCallbackWithAlert(query.ID, b.reviewErrorText(requestID, err)))
+
+	if query.Message != nil {
+		b.refreshCard(query.From.ID, query.Message.MessageID, requestID)
+	}
+}
+
+// Почему изменение заявки не сохранилось: её уже рассмотрели или проверяет другой админ
+func (b *BotService) reviewErrorText(requestID int64, err error) string {
+	var transitionErr *lifecycle.TransitionError
+	if errors.As(err, &transitionErr) {
+		return fmt.Sprintf("Заявка #%d уже %s, изменения не сохранены", requestID, requestStatusTitle(transitionErr.From))
+	}
+
+	if errors.Is(err, db.ErrRequestNotClaimed) {
+		// Claim не различает эти случаи, поэтому смотрим на текущий статус
+		if req, err := b.registrationRepo.GetByID(requestID); err == nil && req.Status != lifecycle.Pending {
+			return fmt.Sprintf("Заявка #%d уже %s, изменения не сохранены", requestID, requestStatusTitle(req.Status))
+		}
+		return fmt.Sprintf("Заявку #%d проверяет другой админ, изменения не сохранены", requestID)
+	}
+
+	log.Printf("Error updating request %d: %v\n", requestID, err)
+	return "Не удалось сохранить изменения заявки. Попробуйте еще раз"
+}
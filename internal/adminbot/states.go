@@ -1,17 +1,52 @@
 package adminbot
 
+import "github.com/gratefultolord/ac_signup_bot/internal/lifecycle"
+
 type AdminState struct {
 	Step      string
 	RequestID int64
+
+	// Редактирование каталога партнёров
+	CategoryID int64
+	PartnerID  int64
+	Field      string
+	Creating   bool
+
+	ConversationID int64
+
+	// Заметка к откладываемой заявке, пока админ выбирает время напоминания
+	HoldNote string
+
+	// Карточка заявки, которую нужно обновить после ввода причины или заметки
+	CardMessageID int
+
+	// Шаблон причины: выбранный при решении по заявке или редактируемый.
+	// TemplateKind задаёт вид нового шаблона
+	TemplateID   int64
+	TemplateText string
+	TemplateKind lifecycle.Status
+
+	// Фильтры журнала действий, пока админ вводит свой период
+	AuditView string
 }
 
 const (
 	StateMainMenu = "main_menu"
 
-	StateViewingRequest = "viewing_request"
-
 	StateEnteringRejectReason   = "entering_reject_reason"
 	StateEnteringRevisionReason = "entering_revision_reason"
 
-	StateAddingAdmin = "adding_admin"
+	StateEnteringRequestNote = "entering_request_note"
+
+	StateEnteringHoldNote     = "entering_hold_note"
+	StateEnteringHoldReminder = "entering_hold_reminder"
+
+	StateEnteringReasonTemplate = "entering_reason_template"
+
+	StateEnteringCategoryTitle = "entering_category_title"
+	StateEnteringPartnerField  = "entering_partner_field"
+
+	StateEnteringSupportReply = "entering_support_reply"
+
+	StateEnteringAuditPeriod = "entering_audit_period"
 )
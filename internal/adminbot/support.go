@@ -0,0 +1,264 @@
+package adminbot
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/gratefultolord/ac_signup_bot/internal/db"
+	"github.com/gratefultolord/ac_signup_bot/internal/events"
+)
+
+const (
+	supportCallbackPrefix = "support"
+
+	supportListLimit    = 30
+	supportHistoryLimit = 15
+
+	// Telegram ограничивает сообщение 4096 символами
+	supportMessageTextLimit = 500
+	supportViewLimit        = 3800
+)
+
+var conversationStatusTitles = map[string]string{
+	db.ConversationOpen:     "ждёт ответа",
+	db.ConversationAnswered: "отвечено",
+	db.ConversationClosed:   "закрыто",
+}
+
+var conversationFilterTitles = map[string]string{
+	db.ConversationOpen:     "Ждут ответа",
+	db.ConversationAnswered: "Отвеченные",
+	db.ConversationClosed:   "Закрытые",
+}
+
+func (b *BotService) handleMessages(chatID int64) {
+	b.showConversations(chatID, nil, db.ConversationOpen)
+}
+
+// Обработка нажатий в разделе обращений:
+// support — ожидающие ответа, support:list:<status>, support:c|reply|close:<id>
+func (b *BotService) handleSupportCallback(query *tgbotapi.CallbackQuery) {
+	b.botAPI.Request(tgbotapi.NewCallback(query.ID, ""))
+
+	if query.Message == nil {
+		return
+	}
+
+	chatID := query.Message.Chat.ID
+
+	parts := strings.Split(query.Data, ":")
+	if len(parts) == 1 {
+		b.showConversations(chatID, query.Message, db.ConversationOpen)
+		return
+	}
+
+	if len(parts) != 3 {
+		log.Printf("bad support callback %q", query.Data)
+		return
+	}
+
+	if parts[1] == "list" {
+		if conversationStatusTitles[parts[2]] == "" {
+			log.Printf("bad support callback %q", query.Data)
+			return
+		}
+		b.showConversations(chatID, query.Message, parts[2])
+		return
+	}
+
+	conversationID, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		log.Printf("bad support callback %q", query.Data)
+		return
+	}
+
+	switch parts[1] {
+	case "c":
+		b.showConversation(chatID, query.Message, conversationID)
+
+	case "reply":
+		b.adminStates[chatID] = &AdminState{
+			Step:           StateEnteringSupportReply,
+			ConversationID: conversationID,
+		}
+
+		msg := tgbotapi.NewMessage(chatID, "Введите ответ пользователю")
+		msg.ReplyMarkup = CancelMenu()
+		b.botAPI.Send(msg)
+
+	case "close":
+		_, err := b.supportRepo.Close(conversationID)
+		if errors.Is(err, db.ErrConversationClosed) {
+			b.showConversation(chatID, query.Message, conversationID)
+			return
+		}
+		if err != nil {
+			log.Printf("Error closing conversation: %v\n", err)
+			msg := tgbotapi.NewMessage(chatID, "Не удалось закрыть обращение")
+			b.botAPI.Send(msg)
+			return
+		}
+
+		b.publish(events.Event{Type: events.ConversationClosed, ConversationID: conversationID})
+		b.audit(chatID, db.AuditSupportClose, auditTargetConversation, conversationID, "")
+
+		b.showConversation(chatID, query.Message, conversationID)
+
+	default:
+		log.Printf("bad support callback %q", query.Data)
+	}
+}
+
+// Ответ администратора сохраняется в обращении, а доставляет его бот регистрации
+func (b *BotService) handleSupportReply(chatID int64, text string) {
+	state := b.adminStates[chatID]
+	conversationID := state.ConversationID
+
+	if text == "Отмена" {
+		b.adminStates[chatID] = &AdminState{Step: StateMainMenu}
+		b.restoreMainMenu(chatID, "Отменено")
+		b.showConversation(chatID, nil, conversationID)
+		return
+	}
+
+	if strings.TrimSpace(text) == "" {
+		msg := tgbotapi.NewMessage(chatID, "Ответ не может быть пустым. Введите текст")
+		msg.ReplyMarkup = CancelMenu()
+		b.botAPI.Send(msg)
+		return
+	}
+
+	_, message, err := b.supportRepo.AddAdminReply(conversationID, chatID, text)
+	if errors.Is(err, db.ErrConversationClosed) {
+		b.adminStates[chatID] = &AdminState{Step: StateMainMenu}
+		b.restoreMainMenu(chatID, "Обращение уже закрыто, ответ не отправлен")
+		return
+	}
+	if err != nil {
+		log.Printf("Error saving support reply: %v\n", err)
+		msg := tgbotapi.NewMessage(chatID, "Ошибка при сохранении ответа. Попробуйте еще раз")
+		msg.ReplyMarkup = CancelMenu()
+		b.botAPI.Send(msg)
+		return
+	}
+
+	b.adminStates[chatID] = &AdminState{Step: StateMainMenu}
+
+	b.publish(events.Event{Type: events.MessageSent, ConversationID: conversationID, MessageID: message.ID})
+	b.audit(chatID, db.AuditSupportReply, auditTargetConversation, conversationID, "")
+	b.restoreMainMenu(chatID, "Ответ отправлен")
+
+	b.showConversation(chatID, nil, conversationID)
+}
+
+func (b *BotService) showConversations(chatID int64, current *tgbotapi.Message, status string) {
+	conversations, err := b.supportRepo.ListByStatus(status, db.Page{Limit: supportListLimit})
+	if err != nil {
+		log.Printf("Error loading conversations: %v\n", err)
+		msg := tgbotapi.NewMessage(chatID, "Ошибка при получении сообщений")
+		b.botAPI.Send(msg)
+		return
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, c := range conversations {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("#%d %s %s, %s", c.ID, c.FirstName, c.LastName, c.UpdatedAt.Format("02.01 15:04")),
+				fmt.Sprintf("%s:c:%d", supportCallbackPrefix, c.ID),
+			),
+		))
+	}
+
+	var filters []tgbotapi.InlineKeyboardButton
+	for _, s := range []string{db.ConversationOpen, db.ConversationAnswered, db.ConversationClosed} {
+		if s == status {
+			continue
+		}
+		filters = append(filters, tgbotapi.NewInlineKeyboardButtonData(
+			conversationFilterTitles[s],
+			fmt.Sprintf("%s:list:%s", supportCallbackPrefix, s),
+		))
+	}
+	rows = append(rows, filters)
+
+	text := fmt.Sprintf("Обращения — %s:", strings.ToLower(conversationFilterTitles[status]))
+	if len(conversations) == 0 {
+		text = fmt.Sprintf("Обращения — %s: нет", strings.ToLower(conversationFilterTitles[status]))
+	}
+
+	b.showInlineView(chatID, current, text, tgbotapi.NewInlineKeyboardMarkup(rows...))
+}
+
+func (b *BotService) showConversation(chatID int64, current *tgbotapi.Message, conversationID int64) {
+	conversation, err := b.supportRepo.GetByID(conversationID)
+	if err != nil {
+		log.Printf("Error loading conversation: %v\n", err)
+		msg := tgbotapi.NewMessage(chatID, "Ошибка при получении сообщений")
+		b.botAPI.Send(msg)
+		return
+	}
+
+	messages, err := b.supportRepo.GetMessages(conversationID, supportHistoryLimit)
+	if err != nil {
+		log.Printf("Error loading conversation messages: %v\n", err)
+		msg := tgbotapi.NewMessage(chatID, "Ошибка при получении сообщений")
+		b.botAPI.Send(msg)
+		return
+	}
+
+	header := fmt.Sprintf(
+		"<b>Обращение #%d</b>\nОт: %s %s (user_id %d)\nСтатус: %s\n",
+		conversation.ID,
+		html.EscapeString(conversation.FirstName),
+		html.EscapeString(conversation.LastName),
+		conversation.TelegramUserID,
+		conversationStatusTitles[conversation.Status],
+	)
+
+	entries := make([]string, 0, len(messages))
+	for _, m := range messages {
+		author := "Пользователь"
+		if m.Sender == db.SenderAdmin {
+			author = "Админ"
+		}
+
+		entries = append(entries, fmt.Sprintf(
+			"\n<i>%s, %s</i>\n%s\n",
+			author, m.CreatedAt.Format("02.01 15:04"), html.EscapeString(truncateText(m.Text, supportMessageTextLimit)),
+		))
+	}
+
+	// Не влезающие в сообщение старые реплики отбрасываем
+	for len(entries) > 1 && len(header)+len(strings.Join(entries, "")) > supportViewLimit {
+		entries = entries[1:]
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	if conversation.Status != db.ConversationClosed {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✉️ Ответить", fmt.Sprintf("%s:reply:%d", supportCallbackPrefix, conversation.ID)),
+			tgbotapi.NewInlineKeyboardButtonData("✅ Закрыть", fmt.Sprintf("%s:close:%d", supportCallbackPrefix, conversation.ID)),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("« К обращениям", fmt.Sprintf("%s:list:%s", supportCallbackPrefix, conversation.Status)),
+	))
+
+	b.showInlineView(chatID, current, header+strings.Join(entries, ""), tgbotapi.NewInlineKeyboardMarkup(rows...))
+}
+
+func truncateText(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+
+	return string(runes[:limit]) + "…"
+}
@@ -2,38 +2,72 @@ package adminbot
 
 import (
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/gratefultolord/ac_signup_bot/internal/access"
+	"github.com/gratefultolord/ac_signup_bot/internal/lifecycle"
 )
 
-func AdminMainMenu() tgbotapi.ReplyKeyboardMarkup {
-	return tgbotapi.NewReplyKeyboard(
-		tgbotapi.NewKeyboardButtonRow(
-			tgbotapi.NewKeyboardButton("Проверить заявки"),
-		),
-		tgbotapi.NewKeyboardButtonRow(
-			tgbotapi.NewKeyboardButton("Сообщения пользователей"),
-		),
-		tgbotapi.NewKeyboardButtonRow(
-			tgbotapi.NewKeyboardButton("Добавить админа"),
-		),
-	)
+const (
+	requestTimelineLimit = 10
+	requestReasonLimit   = 200
+
+	requestNotesLimit    = 5
+	requestNoteLimit     = 200
+	requestNoteMaxLength = 1000
+)
+
+var requestStatusTitles = map[lifecycle.Status]string{
+	lifecycle.Pending:       "на проверке",
+	lifecycle.Approved:      "одобрена",
+	lifecycle.Rejected:      "отклонена",
+	lifecycle.NeedsRevision: "на доработке",
+	lifecycle.Paid:          "оплачена",
+	lifecycle.OnHold:        "отложена",
 }
 
-func RequestActionButtons() tgbotapi.ReplyKeyboardMarkup {
+func requestStatusTitle(status lifecycle.Status) string {
+	if title, ok := requestStatusTitles[status]; ok {
+		return title
+	}
+	return string(status)
+}
+
+// Кнопки главного меню, доступные роли, по две в ряд
+func AdminMainMenu(role access.Role) tgbotapi.ReplyKeyboardMarkup {
+	var rows [][]tgbotapi.KeyboardButton
+
+	for _, button := range mainMenuButtons {
+		if button.permission != "" && !role.Can(button.permission) {
+			continue
+		}
+
+		if len(rows) == 0 || len(rows[len(rows)-1]) == 2 {
+			rows = append(rows, tgbotapi.NewKeyboardButtonRow())
+		}
+		rows[len(rows)-1] = append(rows[len(rows)-1], tgbotapi.NewKeyboardButton(button.title))
+	}
+
+	return tgbotapi.NewReplyKeyboard(rows...)
+}
+
+func CancelMenu() tgbotapi.ReplyKeyboardMarkup {
 	return tgbotapi.NewReplyKeyboard(
 		tgbotapi.NewKeyboardButtonRow(
-			tgbotapi.NewKeyboardButton("Одобрить"),
-			tgbotapi.NewKeyboardButton("Отклонить"),
-			tgbotapi.NewKeyboardButton("На доработку"),
-		),
-		tgbotapi.NewKeyboardButtonRow(
-			tgbotapi.NewKeyboardButton("Главное меню"),
+			tgbotapi.NewKeyboardButton("Отмена"),
 		),
 	)
 }
 
-func CancelMenu() tgbotapi.ReplyKeyboardMarkup {
+// Ввод необязательного поля: при создании его можно пропустить, при редактировании — очистить
+func OptionalFieldMenu(creating bool) tgbotapi.ReplyKeyboardMarkup {
+	skip := "Очистить"
+	if creating {
+		skip = "Пропустить"
+	}
+
 	return tgbotapi.NewReplyKeyboard(
 		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton(skip),
 			tgbotapi.NewKeyboardButton("Отмена"),
 		),
 	)
@@ -0,0 +1,194 @@
+package adminnotify
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/gratefultolord/ac_signup_bot/internal/access"
+	"github.com/gratefultolord/ac_signup_bot/internal/db"
+)
+
+// Данные кнопки «Открыть», которые разбирает бот администратора
+const (
+	CallbackPrefix    = "open"
+	OpenRequests      = "open:requests"
+	OpenConversations = "open:conversations"
+)
+
+func OpenRequest(requestID int64) string {
+	return fmt.Sprintf("%s:request:%d", CallbackPrefix, requestID)
+}
+
+func OpenConversation(conversationID int64) string {
+	return fmt.Sprintf("%s:conversation:%d", CallbackPrefix, conversationID)
+}
+
+const (
+	kindRequest = "request"
+	kindMessage = "message"
+
+	messagePreviewLimit = 200
+)
+
+type event struct {
+	kind     string
+	targetID int64
+	text     string
+}
+
+// Notifier рассылает админам push-уведомления через бот администратора.
+// События копятся в течение окна и при большом потоке уходят одной сводкой
+type Notifier struct {
+	botAPI    *tgbotapi.BotAPI
+	adminRepo *db.AdminRepository
+	window    time.Duration
+
+	mu      sync.Mutex
+	pending []event
+	timer   *time.Timer
+}
+
+func New(botAPI *tgbotapi.BotAPI, adminRepo *db.AdminRepository, window time.Duration) *Notifier {
+	return &Notifier{
+		botAPI:    botAPI,
+		adminRepo: adminRepo,
+		window:    window,
+	}
+}
+
+func (n *Notifier) NewRequest(req *db.RegistrationRequest) {
+	text := fmt.Sprintf("🆕 Новая заявка #%d\n%s %s", req.ID, req.FirstName, req.LastName)
+	n.enqueue(event{kind: kindRequest, targetID: req.ID, text: text})
+}
+
+func (n *Notifier) RequestResubmitted(req *db.RegistrationRequest) {
+	text := fmt.Sprintf("🔁 Заявка #%d исправлена после доработки\n%s %s", req.ID, req.FirstName, req.LastName)
+	n.enqueue(event{kind: kindRequest, targetID: req.ID, text: text})
+}
+
+func (n *Notifier) NewMessage(conversation *db.SupportConversation, message string) {
+	runes := []rune(message)
+	if len(runes) > messagePreviewLimit {
+		message = string(runes[:messagePreviewLimit]) + "…"
+	}
+
+	text := fmt.Sprintf(
+		"✉️ Сообщение от %s %s (обращение #%d)\n\n%s",
+		conversation.FirstName, conversation.LastName, conversation.ID, message,
+	)
+
+	n.enqueue(event{kind: kindMessage, targetID: conversation.ID, text: text})
+}
+
+func (n *Notifier) enqueue(e event) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.pending = append(n.pending, e)
+
+	if n.timer == nil {
+		n.timer = time.AfterFunc(n.window, n.flush)
+	}
+}
+
+func (n *Notifier) flush() {
+	n.mu.Lock()
+	events := n.pending
+	n.pending = nil
+	n.timer = nil
+	n.mu.Unlock()
+
+	if len(events) == 0 {
+		return
+	}
+
+	admins, err := n.adminRepo.GetAll()
+	if err != nil {
+		log.Printf("adminnotify: failed to load admins: %v", err)
+		return
+	}
+
+	var requests, messages []event
+	for _, e := range events {
+		if e.kind == kindRequest {
+			requests = append(requests, e)
+		} else {
+			messages = append(messages, e)
+		}
+	}
+
+	if msg := build(requests, kindRequest); msg != nil {
+		n.send(admins, *msg, func(a db.Admin) bool { return a.NotifyRequests && a.Role.Can(access.ReviewRequests) })
+	}
+
+	if msg := build(messages, kindMessage); msg != nil {
+		n.send(admins, *msg, func(a db.Admin) bool { return a.NotifyMessages && a.Role.Can(access.AnswerSupport) })
+	}
+}
+
+// Одно событие — подробное уведомление, несколько — сводка со ссылкой на весь список
+func build(events []event, kind string) *tgbotapi.MessageConfig {
+	if len(events) == 0 {
+		return nil
+	}
+
+	if len(events) == 1 {
+		e := events[0]
+
+		data := OpenRequest(e.targetID)
+		if kind == kindMessage {
+			data = OpenConversation(e.targetID)
+		}
+
+		return openMessage(e.text, data)
+	}
+
+	if kind == kindRequest {
+		return openMessage(fmt.Sprintf("🆕 Новых заявок: %d", len(events)), OpenRequests)
+	}
+
+	conversations := make(map[int64]struct{})
+	for _, e := range events {
+		conversations[e.targetID] = struct{}{}
+	}
+
+	if len(conversations) == 1 {
+		return openMessage(
+			fmt.Sprintf("✉️ Новых сообщений в обращении #%d: %d", events[0].targetID, len(events)),
+			OpenConversation(events[0].targetID),
+		)
+	}
+
+	return openMessage(
+		fmt.Sprintf("✉️ Новых сообщений: %d в %d обращениях", len(events), len(conversations)),
+		OpenConversations,
+	)
+}
+
+func (n *Notifier) send(admins []db.Admin, msg tgbotapi.MessageConfig, enabled func(db.Admin) bool) {
+	for _, admin := range admins {
+		if !enabled(admin) {
+			continue
+		}
+
+		msg.ChatID = admin.ChatID
+		if _, err := n.botAPI.Send(msg); err != nil {
+			log.Printf("adminnotify: failed to notify admin %d: %v", admin.ChatID, err)
+		}
+	}
+}
+
+func openMessage(text, data string) *tgbotapi.MessageConfig {
+	msg := tgbotapi.NewMessage(0, text)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Открыть", data),
+		),
+	)
+
+	return &msg
+}
@@ -0,0 +1,102 @@
+package authapi
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"github.com/gratefultolord/ac_signup_bot/internal/config"
+)
+
+type Claims struct {
+	PhoneNumber string `json:"phone_number"`
+	jwt.RegisteredClaims
+}
+
+// Signer выпускает и проверяет JWT приложения, HS256 или RS256
+type Signer struct {
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+	ttl       time.Duration
+}
+
+func NewSigner(cfg *config.Config) (*Signer, error) {
+	signer := &Signer{ttl: cfg.JWTTTL}
+
+	switch cfg.JWTAlgorithm {
+	case "HS256":
+		signer.method = jwt.SigningMethodHS256
+		signer.signKey = []byte(cfg.JWTSecret)
+		signer.verifyKey = []byte(cfg.JWTSecret)
+
+	case "RS256":
+		privatePEM, err := os.ReadFile(cfg.JWTPrivateKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("authapi.NewSigner: cannot read private key: %w", err)
+		}
+
+		privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(privatePEM)
+		if err != nil {
+			return nil, fmt.Errorf("authapi.NewSigner: cannot parse private key: %w", err)
+		}
+
+		publicPEM, err := os.ReadFile(cfg.JWTPublicKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("authapi.NewSigner: cannot read public key: %w", err)
+		}
+
+		publicKey, err := jwt.ParseRSAPublicKeyFromPEM(publicPEM)
+		if err != nil {
+			return nil, fmt.Errorf("authapi.NewSigner: cannot parse public key: %w", err)
+		}
+
+		signer.method = jwt.SigningMethodRS256
+		signer.signKey = privateKey
+		signer.verifyKey = publicKey
+
+	default:
+		return nil, fmt.Errorf("authapi.NewSigner: unsupported algorithm %q", cfg.JWTAlgorithm)
+	}
+
+	return signer, nil
+}
+
+func (s *Signer) Issue(userID int64, phoneNumber string) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(s.ttl)
+
+	claims := Claims{
+		PhoneNumber: phoneNumber,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.FormatInt(userID, 10),
+			ID:        uuid.New().String(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	signed, err := jwt.NewWithClaims(s.method, claims).SignedString(s.signKey)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("Signer.Issue: %w", err)
+	}
+
+	return signed, expiresAt, nil
+}
+
+func (s *Signer) Parse(tokenString string) (*Claims, error) {
+	var claims Claims
+
+	_, err := jwt.ParseWithClaims(tokenString, &claims, func(token *jwt.Token) (interface{}, error) {
+		return s.verifyKey, nil
+	}, jwt.WithValidMethods([]string{s.method.Alg()}))
+	if err != nil {
+		return nil, fmt.Errorf("Signer.Parse: %w", err)
+	}
+
+	return &claims, nil
+}
@@ -0,0 +1,171 @@
+package authapi
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/gratefultolord/ac_signup_bot/internal/config"
+)
+
+func newHS256Signer(t *testing.T, secret string, ttl time.Duration) *Signer {
+	t.Helper()
+
+	signer, err := NewSigner(&config.Config{JWTAlgorithm: "HS256", JWTSecret: secret, JWTTTL: ttl})
+	if err != nil {
+		t.Fatalf("NewSigner(HS256): %v", err)
+	}
+
+	return signer
+}
+
+func newRS256Signer(t *testing.T, ttl time.Duration) *Signer {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey: %v", err)
+	}
+
+	publicDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("x509.MarshalPKIXPublicKey: %v", err)
+	}
+
+	dir := t.TempDir()
+	privatePath := filepath.Join(dir, "private.pem")
+	publicPath := filepath.Join(dir, "public.pem")
+
+	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+
+	if err := os.WriteFile(privatePath, privatePEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(publicPath, publicPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	signer, err := NewSigner(&config.Config{
+		JWTAlgorithm:      "RS256",
+		JWTPrivateKeyPath: privatePath,
+		JWTPublicKeyPath:  publicPath,
+		JWTTTL:            ttl,
+	})
+	if err != nil {
+		t.Fatalf("NewSigner(RS256): %v", err)
+	}
+
+	return signer
+}
+
+func TestSignerRoundTrip(t *testing.T) {
+	signers := map[string]*Signer{
+		"HS256": newHS256Signer(t, "secret", time.Hour),
+		"RS256": newRS256Signer(t, time.Hour),
+	}
+
+	for alg, signer := range signers {
+		t.Run(alg, func(t *testing.T) {
+			token, expiresAt, err := signer.Issue(42, "79991234567")
+			if err != nil {
+				t.Fatalf("Issue: %v", err)
+			}
+
+			if until := time.Until(expiresAt); until <= 59*time.Minute || until > time.Hour {
+				t.Fatalf("Issue expiresAt in %v, want about 1h", until)
+			}
+
+			claims, err := signer.Parse(token)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+
+			if claims.Subject != "42" || claims.PhoneNumber != "79991234567" || claims.ID == "" {
+				t.Fatalf("Parse claims = %+v, want subject 42, phone and jti", claims)
+			}
+		})
+	}
+}
+
+func TestSignerRejects(t *testing.T) {
+	hs := newHS256Signer(t, "secret", time.Hour)
+	rs := newRS256Signer(t, time.Hour)
+	otherRS := newRS256Signer(t, time.Hour)
+
+	hsToken, _, err := hs.Issue(1, "79991234567")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rsToken, _, err := rs.Issue(1, "79991234567")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expiredToken, _, err := newHS256Signer(t, "secret", -time.Minute).Issue(1, "79991234567")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	noneToken, err := jwt.NewWithClaims(jwt.SigningMethodNone, Claims{PhoneNumber: "79991234567"}).
+		SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		signer  *Signer
+		token   string
+		wantErr error
+	}{
+		{name: "expired", signer: hs, token: expiredToken, wantErr: jwt.ErrTokenExpired},
+		{name: "wrong secret", signer: newHS256Signer(t, "other", time.Hour), token: hsToken, wantErr: jwt.ErrTokenSignatureInvalid},
+		{name: "wrong rsa key", signer: otherRS, token: rsToken, wantErr: jwt.ErrTokenSignatureInvalid},
+		{name: "hs256 token for rs256 signer", signer: rs, token: hsToken, wantErr: jwt.ErrTokenSignatureInvalid},
+		{name: "rs256 token for hs256 signer", signer: hs, token: rsToken, wantErr: jwt.ErrTokenSignatureInvalid},
+		{name: "alg none", signer: hs, token: noneToken, wantErr: jwt.ErrTokenSignatureInvalid},
+		{name: "tampered", signer: hs, token: hsToken[:len(hsToken)-2] + "xx", wantErr: jwt.ErrTokenSignatureInvalid},
+		{name: "garbage", signer: hs, token: "not-a-jwt", wantErr: jwt.ErrTokenMalformed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := tt.signer.Parse(tt.token)
+			if err == nil {
+				t.Fatalf("Parse() = %+v, want error", claims)
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewSignerErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.Config
+	}{
+		{name: "unsupported algorithm", cfg: config.Config{JWTAlgorithm: "ES256"}},
+		{name: "missing private key", cfg: config.Config{JWTAlgorithm: "RS256", JWTPrivateKeyPath: filepath.Join(t.TempDir(), "missing.pem")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewSigner(&tt.cfg); err == nil {
+				t.Fatal("NewSigner() error = nil, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package authapi
+
+import (
+	"sync"
+	"time"
+)
+
+// AttemptLimiter блокирует номер телефона после maxAttempts неудачных
+// проверок кода подряд на время lockout. Неудачи, после которых прошло
+// больше lockout, забываются, чтобы карта не росла бесконечно
+type AttemptLimiter struct {
+	mu          sync.Mutex
+	maxAttempts int
+	lockout     time.Duration
+	attempts    map[string]*attempt
+	lastSweep   time.Time
+}
+
+type attempt struct {
+	failures    int
+	lastFailure time.Time
+	lockedUntil time.Time
+}
+
+func NewAttemptLimiter(maxAttempts int, lockout time.Duration) *AttemptLimiter {
+	return &AttemptLimiter{
+		maxAttempts: maxAttempts,
+		lockout:     lockout,
+		attempts:    make(map[string]*attempt),
+	}
+}
+
+// Blocked возвращает время, до которого номер заблокирован
+func (l *AttemptLimiter) Blocked(phone string) (time.Time, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	a, ok := l.attempts[phone]
+	if !ok || time.Now().After(a.lockedUntil) {
+		return time.Time{}, false
+	}
+
+	return a.lockedUntil, true
+}
+
+func (l *AttemptLimiter) Fail(phone string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+
+	a, ok := l.attempts[phone]
+	if !ok {
+		a = &attempt{}
+		l.attempts[phone] = a
+	}
+
+	a.failures++
+	a.lastFailure = now
+	if a.failures >= l.maxAttempts {
+		a.failures = 0
+		a.lockedUntil = now.Add(l.lockout)
+	}
+}
+
+func (l *AttemptLimiter) Reset(phone string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.attempts, phone)
+}
+
+// Удалить записи без активной блокировки и без свежих неудач. Проходит
+// по карте не чаще раза в lockout, вызывается под l.mu
+func (l *AttemptLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.lockout {
+		return
+	}
+	l.lastSweep = now
+
+	for phone, a := range l.attempts {
+		if now.After(a.lockedUntil) && now.Sub(a.lastFailure) > l.lockout {
+			delete(l.attempts, phone)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package authapi
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAttemptLimiterBlocks(t *testing.T) {
+	limiter := NewAttemptLimiter(3, time.Hour)
+	phone := "79991234567"
+
+	for i := 0; i < 2; i++ {
+		limiter.Fail(phone)
+		if _, blocked := limiter.Blocked(phone); blocked {
+			t.Fatalf("blocked after %d failures, want 3", i+1)
+		}
+	}
+
+	limiter.Fail(phone)
+	until, blocked := limiter.Blocked(phone)
+	if !blocked {
+		t.Fatal("not blocked after 3 failures")
+	}
+
+	if left := time.Until(until); left <= 59*time.Minute || left > time.Hour {
+		t.Fatalf("blocked for %v, want about 1h", left)
+	}
+
+	if _, blocked := limiter.Blocked("79990000000"); blocked {
+		t.Fatal("other phone blocked")
+	}
+
+	limiter.Reset(phone)
+	if _, blocked := limiter.Blocked(phone); blocked {
+		t.Fatal("still blocked after Reset")
+	}
+}
+
+func TestAttemptLimiterLockoutExpires(t *testing.T) {
+	limiter := NewAttemptLimiter(1, 20*time.Millisecond)
+	phone := "79991234567"
+
+	limiter.Fail(phone)
+	if _, blocked := limiter.Blocked(phone); !blocked {
+		t.Fatal("not blocked after failure")
+	}
+
+	time.Sleep(30 * time.Millisecond)
+
+	if _, blocked := limiter.Blocked(phone); blocked {
+		t.Fatal("still blocked after lockout")
+	}
+}
+
+func TestAttemptLimiterSweep(t *testing.T) {
+	lockout := time.Hour
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		attempt attempt
+		kept    bool
+	}{
+		{name: "recent failure", attempt: attempt{failures: 1, lastFailure: now.Add(-time.Minute)}, kept: true},
+		{name: "old failure", attempt: attempt{failures: 2, lastFailure: now.Add(-2 * lockout)}, kept: false},
+		{name: "active lockout", attempt: attempt{lastFailure: now.Add(-2 * lockout), lockedUntil: now.Add(time.Minute)}, kept: true},
+		{name: "expired lockout", attempt: attempt{lastFailure: now.Add(-2 * lockout), lockedUntil: now.Add(-lockout)}, kept: false},
+	}
+
+	limiter := NewAttemptLimiter(3, lockout)
+	for _, tt := range tests {
+		a := tt.attempt
+		limiter.attempts[tt.name] = &a
+	}
+
+	limiter.sweep(now)
+
+	for _, tt := range tests {
+		if _, ok := limiter.attempts[tt.name]; ok != tt.kept {
+			t.Errorf("%s: kept = %v, want %v", tt.name, ok, tt.kept)
+		}
+	}
+
+	// Повторный проход раньше чем через lockout карту не трогает
+	stale := attempt{lastFailure: now.Add(-2 * lockout)}
+	limiter.attempts["stale"] = &stale
+	limiter.sweep(now.Add(time.Minute))
+
+	if _, ok := limiter.attempts["stale"]; !ok {
+		t.Error("sweep ran again before lockout passed")
+	}
+
+	limiter.sweep(now.Add(lockout + time.Second))
+	if _, ok := limiter.attempts["stale"]; ok {
+		t.Error("stale entry kept after next sweep")
+	}
+}
+
+func TestAttemptLimiterFailSweeps(t *testing.T) {
+	limiter := NewAttemptLimiter(3, time.Hour)
+	limiter.attempts["old"] = &attempt{failures: 1, lastFailure: time.Now().Add(-3 * time.Hour)}
+
+	limiter.Fail("79991234567")
+
+	if _, ok := limiter.attempts["old"]; ok {
+		t.Fatal("Fail did not prune stale entry")
+	}
+	if len(limiter.attempts) != 1 {
+		t.Fatalf("attempts = %d, want 1", len(limiter.attempts))
+	}
+}
@@ -0,0 +1,244 @@
+package authapi
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gratefultolord/ac_signup_bot/internal/db"
+	"github.com/gratefultolord/ac_signup_bot/internal/phone"
+)
+
+var codeRe = regexp.MustCompile(`^\d{6}$`)
+
+// Телефон и код занимают несколько десятков байт
+const maxRequestBody = 4 << 10
+
+type Server struct {
+	tokenRepo *db.TokenRepository
+	userRepo  *db.UserRepository
+	signer    *Signer
+	limiter   *AttemptLimiter
+}
+
+func New(
+	tokenRepo *db.TokenRepository,
+	userRepo *db.UserRepository,
+	signer *Signer,
+	limiter *AttemptLimiter,
+) *Server {
+	return &Server{
+		tokenRepo: tokenRepo,
+		userRepo:  userRepo,
+		signer:    signer,
+		limiter:   limiter,
+	}
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /api/auth/verify", s.handleVerify)
+	mux.HandleFunc("POST /api/auth/refresh", s.handleRefresh)
+	mux.HandleFunc("POST /api/auth/logout", s.handleLogout)
+	mux.HandleFunc("GET /api/auth/membership", s.handleMembership)
+
+	return mux
+}
+
+type verifyRequest struct {
+	PhoneNumber string `json:"phone_number"`
+	Code        string `json:"code"`
+}
+
+type tokenResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type membershipResponse struct {
+	Active    bool      `json:"active"`
+	Status    string    `json:"status"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// Обменять телефон и шестизначный код из бота на JWT
+func (s *Server) handleVerify(w http.ResponseWriter, r *http.Request) {
+	var req verifyRequest
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBody)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	phoneNumber := phone.Normalize(req.PhoneNumber)
+	if !phone.IsValid(phoneNumber) || !codeRe.MatchString(req.Code) {
+		writeError(w, http.StatusBadRequest, "invalid phone number or code format")
+		return
+	}
+
+	if until, blocked := s.limiter.Blocked(phoneNumber); blocked {
+		w.Header().Set("Retry-After", strconv.Itoa(int(time.Until(until).Seconds())+1))
+		writeError(w, http.StatusTooManyRequests, "too many attempts, try again later")
+		return
+	}
+
+	token, err := s.tokenRepo.GetByPhoneAndCode(phoneNumber, req.Code)
+	if errors.Is(err, sql.ErrNoRows) {
+		s.limiter.Fail(phoneNumber)
+		writeError(w, http.StatusUnauthorized, "invalid phone number or code")
+		return
+	}
+	if err != nil {
+		log.Printf("handleVerify: %v", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	s.limiter.Reset(phoneNumber)
+
+	if ok := s.checkMembership(w, token.UserID); !ok {
+		return
+	}
+
+	s.issueToken(w, token)
+}
+
+// Выдать новый JWT взамен ещё действующего
+func (s *Server) handleRefresh(w http.ResponseWriter, r *http.Request) {
+	token, ok := s.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	if ok := s.checkMembership(w, token.UserID); !ok {
+		return
+	}
+
+	s.issueToken(w, token)
+}
+
+func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
+	token, ok := s.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	if err := s.tokenRepo.ClearToken(*token.Token); err != nil {
+		log.Printf("handleLogout: %v", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleMembership(w http.ResponseWriter, r *http.Request) {
+	token, ok := s.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := s.userRepo.GetByID(token.UserID)
+	if err != nil {
+		log.Printf("handleMembership: %v", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, membershipResponse{
+		Active:    isMembershipActive(user),
+		Status:    user.MembershipStatus,
+		ExpiresAt: user.ExpiresAt,
+	})
+}
+
+// Проверить Bearer-токен: подпись, срок и наличие в таблице tokens
+func (s *Server) authenticate(w http.ResponseWriter, r *http.Request) (*db.Token, bool) {
+	raw, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found || raw == "" {
+		writeError(w, http.StatusUnauthorized, "missing bearer token")
+		return nil, false
+	}
+
+	if _, err := s.signer.Parse(raw); err != nil {
+		writeError(w, http.StatusUnauthorized, "invalid token")
+		return nil, false
+	}
+
+	token, err := s.tokenRepo.GetByToken(raw)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusUnauthorized, "token revoked")
+		return nil, false
+	}
+	if err != nil {
+		log.Printf("authenticate: %v", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return nil, false
+	}
+
+	return token, true
+}
+
+func (s *Server) checkMembership(w http.ResponseWriter, userID int64) bool {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		log.Printf("checkMembership: %v", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return false
+	}
+
+	if !isMembershipActive(user) {
+		writeError(w, http.StatusForbidden, "membership expired")
+		return false
+	}
+
+	return true
+}
+
+func (s *Server) issueToken(w http.ResponseWriter, token *db.Token) {
+	signed, expiresAt, err := s.signer.Issue(token.UserID, token.PhoneNumber)
+	if err != nil {
+		log.Printf("issueToken: %v", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	if err := s.tokenRepo.UpdateJWT(token.ID, signed, expiresAt); err != nil {
+		log.Printf("issueToken: %v", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, tokenResponse{
+		Token:     signed,
+		ExpiresAt: expiresAt,
+	})
+}
+
+func isMembershipActive(user *db.User) bool {
+	return user.MembershipStatus == "active" && user.ExpiresAt.After(time.Now())
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("writeJSON: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorResponse{Error: message})
+}
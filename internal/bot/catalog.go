@@ -0,0 +1,259 @@
+package bot
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/gratefultolord/ac_signup_bot/internal/catalog"
+	"github.com/gratefultolord/ac_signup_bot/internal/db"
+)
+
+const (
+	catalogCallbackPrefix = "catalog"
+	catalogNoopCallback   = "catalog:noop"
+
+	// Категорий немного, показываем их одной клавиатурой
+	catalogCategoriesLimit = 50
+)
+
+// Сообщение каталога: текст или фото с подписью и inline-клавиатурой
+type catalogView struct {
+	text      string
+	photoPath string
+	keyboard  tgbotapi.InlineKeyboardMarkup
+}
+
+func (b *BotService) handlePrivilegesInfo(chatID int64) {
+	view, err := b.categoriesView()
+	if err != nil {
+		log.Printf("failed to load catalog: %v", err)
+		msg := tgbotapi.NewMessage(chatID, "Не удалось загрузить привилегии. Попробуйте позже")
+		b.botAPI.Send(msg)
+		return
+	}
+
+	b.showCatalogView(chatID, nil, view)
+}
+
+// Обработка нажатий в каталоге:
+// catalog — список категорий, catalog:<categoryID>:<index> — карточка партнёра
+func (b *BotService) handleCatalogCallback(query *tgbotapi.CallbackQuery) {
+	b.botAPI.Request(tgbotapi.NewCallback(query.ID, ""))
+
+	if query.Message == nil || query.Data == catalogNoopCallback {
+		return
+	}
+
+	chatID := query.Message.Chat.ID
+
+	var (
+		view catalogView
+		err  error
+	)
+
+	parts := strings.Split(query.Data, ":")
+	switch len(parts) {
+	case 1:
+		view, err = b.categoriesView()
+	case 3:
+		categoryID, parseErr := strconv.ParseInt(parts[1], 10, 64)
+		index, indexErr := strconv.Atoi(parts[2])
+		if parseErr != nil || indexErr != nil {
+			log.Printf("bad catalog callback %q", query.Data)
+			return
+		}
+		view, err = b.partnerView(categoryID, index)
+	default:
+		log.Printf("bad catalog callback %q", query.Data)
+		return
+	}
+
+	if err != nil {
+		log.Printf("failed to load catalog: %v", err)
+		msg := tgbotapi.NewMessage(chatID, "Не удалось загрузить привилегии. Попробуйте позже")
+		b.botAPI.Send(msg)
+		return
+	}
+
+	b.showCatalogView(chatID, query.Message, view)
+}
+
+func (b *BotService) categoriesView() (catalogView, error) {
+	categories, err := b.categoryRepo.ListPublished(db.Page{Limit: catalogCategoriesLimit})
+	if err != nil {
+		return catalogView{}, err
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, category := range categories {
+		count, err := b.partnerRepo.CountPublishedByCategory(category.ID)
+		if err != nil {
+			return catalogView{}, err
+		}
+
+		if count == 0 {
+			continue
+		}
+
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("%s (%d)", category.Title, count),
+				fmt.Sprintf("%s:%d:0", catalogCallbackPrefix, category.ID),
+			),
+		))
+	}
+
+	if len(rows) == 0 {
+		return catalogView{text: "Пока нет доступных привилегий"}, nil
+	}
+
+	return catalogView{
+		text:     "Привилегии Ambassador card. Выберите категорию:",
+		keyboard: tgbotapi.NewInlineKeyboardMarkup(rows...),
+	}, nil
+}
+
+func (b *BotService) partnerView(categoryID int64, index int) (catalogView, error) {
+	backRow := tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("« К категориям", catalogCallbackPrefix),
+	)
+
+	// Кнопки старого сообщения могут вести в уже скрытую или удалённую категорию
+	category, err := b.categoryRepo.GetByID(categoryID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return catalogView{}, err
+	}
+
+	if err != nil || !category.IsPublished {
+		return catalogView{
+			text:     "Эта категория больше недоступна",
+			keyboard: tgbotapi.NewInlineKeyboardMarkup(backRow),
+		}, nil
+	}
+
+	total, err := b.partnerRepo.CountPublishedByCategory(categoryID)
+	if err != nil {
+		return catalogView{}, err
+	}
+
+	if total == 0 {
+		return catalogView{
+			text:     "В этой категории пока нет партнёров",
+			keyboard: tgbotapi.NewInlineKeyboardMarkup(backRow),
+		}, nil
+	}
+
+	if index < 0 || index >= total {
+		index = 0
+	}
+
+	partners, err := b.partnerRepo.ListPublishedByCategory(categoryID, db.Page{Limit: 1, Offset: index})
+	if err != nil {
+		return catalogView{}, err
+	}
+
+	if len(partners) == 0 {
+		return catalogView{
+			text:     "Партнёр не найден",
+			keyboard: tgbotapi.NewInlineKeyboardMarkup(backRow),
+		}, nil
+	}
+
+	partner := partners[0]
+
+	prev := (index - 1 + total) % total
+	next := (index + 1) % total
+
+	navRow := tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("◀️", fmt.Sprintf("%s:%d:%d", catalogCallbackPrefix, categoryID, prev)),
+		tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%d / %d", index+1, total), catalogNoopCallback),
+		tgbotapi.NewInlineKeyboardButtonData("▶️", fmt.Sprintf("%s:%d:%d", catalogCallbackPrefix, categoryID, next)),
+	)
+
+	card := catalog.FormatPartnerCard(&partner)
+
+	// Длинную карточку отправляем текстом: в подпись к фото она не поместится
+	photoPath := ""
+	if catalog.FitsCaption(card) {
+		photoPath = catalog.ResolvePhoto(partner.PhotoPath)
+	}
+
+	return catalogView{
+		text:      card,
+		photoPath: photoPath,
+		keyboard:  tgbotapi.NewInlineKeyboardMarkup(navRow, backRow),
+	}, nil
+}
+
+// Показать view, по возможности отредактировав текущее сообщение каталога.
+// Фото нельзя превратить в текст и наоборот: в этом случае сообщение пересоздаётся
+func (b *BotService) showCatalogView(chatID int64, current *tgbotapi.Message, view catalogView) {
+	var markup *tgbotapi.InlineKeyboardMarkup
+	if len(view.keyboard.InlineKeyboard) > 0 {
+		markup = &view.keyboard
+	}
+
+	if current != nil {
+		currentIsPhoto := len(current.Photo) > 0
+		newIsPhoto := view.photoPath != ""
+
+		switch {
+		case currentIsPhoto && newIsPhoto:
+			media := tgbotapi.NewInputMediaPhoto(tgbotapi.FilePath(view.photoPath))
+			media.Caption = view.text
+			media.ParseMode = tgbotapi.ModeHTML
+
+			edit := tgbotapi.EditMessageMediaConfig{
+				BaseEdit: tgbotapi.BaseEdit{
+					ChatID:      chatID,
+					MessageID:   current.MessageID,
+					ReplyMarkup: markup,
+				},
+				Media: media,
+			}
+			if _, err := b.botAPI.Request(edit); err != nil {
+				log.Printf("failed to edit catalog photo: %v", err)
+			}
+			return
+
+		case !currentIsPhoto && !newIsPhoto:
+			edit := tgbotapi.NewEditMessageText(chatID, current.MessageID, view.text)
+			edit.ParseMode = tgbotapi.ModeHTML
+			edit.ReplyMarkup = markup
+			if _, err := b.botAPI.Request(edit); err != nil {
+				log.Printf("failed to edit catalog message: %v", err)
+			}
+			return
+		}
+
+		b.botAPI.Request(tgbotapi.NewDeleteMessage(chatID, current.MessageID))
+	}
+
+	if view.photoPath != "" {
+		photo := tgbotapi.NewPhoto(chatID, tgbotapi.FilePath(view.photoPath))
+		photo.Caption = view.text
+		photo.ParseMode = tgbotapi.ModeHTML
+		if markup != nil {
+			photo.ReplyMarkup = markup
+		}
+		if _, err := b.botAPI.Send(photo); err != nil {
+			log.Printf("failed to send catalog photo: %v", err)
+		}
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, view.text)
+	msg.ParseMode = tgbotapi.ModeHTML
+	if markup != nil {
+		msg.ReplyMarkup = markup
+	}
+	if _, err := b.botAPI.Send(msg); err != nil {
+		log.Printf("failed to send catalog message: %v", err)
+	}
+}
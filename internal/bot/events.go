@@ -0,0 +1,58 @@
+package bot
+
+import (
+	"log"
+
+	"github.com/gratefultolord/ac_signup_bot/internal/db"
+	"github.com/gratefultolord/ac_signup_bot/internal/events"
+)
+
+func (b *BotService) publish(event events.Event) {
+	if err := b.publisher.Publish(event); err != nil {
+		log.Printf("failed to publish %s event: %v", event.Type, err)
+	}
+}
+
+// Реакция на решения админов: обновляем состояние чата и будим диспетчер outbox
+func (b *BotService) handleEvent(event events.Event) {
+	switch event.Type {
+	case events.RequestApproved, events.RequestRejected, events.RevisionRequested:
+		req, err := b.registrationRepo.GetByID(event.RequestID)
+		if err != nil {
+			log.Printf("failed to load request %d for %s event: %v", event.RequestID, event.Type, err)
+			return
+		}
+
+		chatID := req.TelegramUserID
+		defer b.persistState(chatID)
+		defer b.wakeOutbox()
+
+		switch event.Type {
+		case events.RequestApproved:
+			b.onRequestApproved(req)
+		case events.RequestRejected:
+			b.onRequestRejected(req)
+		case events.RevisionRequested:
+			b.onRevisionRequested(req)
+		}
+
+	// Ответ админа и закрытие обращения записаны в outbox вместе с изменением
+	// обращения, событие только ускоряет доставку
+	case events.NotificationRequeued, events.MessageSent, events.ConversationClosed:
+		b.wakeOutbox()
+	}
+}
+
+// Сами сообщения о решении доставляет диспетчер outbox: запись в outbox
+// сделана вместе со сменой статуса, здесь только переводим чат на нужный шаг
+func (b *BotService) onRequestApproved(req *db.RegistrationRequest) {
+	b.userStates[req.TelegramUserID] = &UserState{Step: "awaiting_payment"}
+}
+
+func (b *BotService) onRequestRejected(req *db.RegistrationRequest) {
+	b.userStates[req.TelegramUserID] = &UserState{Step: "start"}
+}
+
+func (b *BotService) onRevisionRequested(req *db.RegistrationRequest) {
+	b.userStates[req.TelegramUserID] = &UserState{Step: "needs_revision", RequestID: req.ID}
+}
@@ -1,143 +1,252 @@
 package bot
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
 	"log"
-	"os"
-	"path/filepath"
-	"strconv"
 	"strings"
-	"time"
 
 	"github.com/AlekSi/pointer"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
 	"github.com/gratefultolord/ac_signup_bot/internal/db"
+	"github.com/gratefultolord/ac_signup_bot/internal/events"
 	"github.com/gratefultolord/ac_signup_bot/internal/files"
+	"github.com/gratefultolord/ac_signup_bot/internal/lifecycle"
+	"github.com/gratefultolord/ac_signup_bot/internal/phone"
 )
 
 type BotService struct {
 	botAPI                *tgbotapi.BotAPI
 	registrationRepo      *db.RegistrationRequestRepository
 	usersRepo             *db.UserRepository
-	tokenRepo             *db.TokenRepository
+	paymentRepo           *db.PaymentRepository
 	adminRepo             *db.AdminRepository
+	subscriptionRepo      *db.SubscriptionRepository
+	categoryRepo          *db.CategoryRepository
+	partnerRepo           *db.PartnerRepository
+	supportRepo           *db.SupportRepository
+	outboxRepo            *db.OutboxRepository
 	fileService           *files.FileService
+	stateStore            StateStore
+	publisher             *events.Publisher
+	outboxWake            chan struct{}
 	userStates            map[int64]*UserState
 	telegramProviderToken string
+	tariffs               map[string]Tariff
 }
 
 func New(
 	botAPI *tgbotapi.BotAPI,
 	registrationRepo *db.RegistrationRequestRepository,
 	userRepo *db.UserRepository,
-	tokenRepo *db.TokenRepository,
+	paymentRepo *db.PaymentRepository,
 	adminRepo *db.AdminRepository,
+	subscriptionRepo *db.SubscriptionRepository,
+	categoryRepo *db.CategoryRepository,
+	partnerRepo *db.PartnerRepository,
+	supportRepo *db.SupportRepository,
+	outboxRepo *db.OutboxRepository,
 	fileService *files.FileService,
+	stateStore StateStore,
+	publisher *events.Publisher,
 	telegramProviderToken string,
+	tariffs map[string]Tariff,
 ) *BotService {
 	return &BotService{
 		botAPI:                botAPI,
 		registrationRepo:      registrationRepo,
 		usersRepo:             userRepo,
-		tokenRepo:             tokenRepo,
+		paymentRepo:           paymentRepo,
 		adminRepo:             adminRepo,
+		subscriptionRepo:      subscriptionRepo,
+		categoryRepo:          categoryRepo,
+		partnerRepo:           partnerRepo,
+		supportRepo:           supportRepo,
+		outboxRepo:            outboxRepo,
 		fileService:           fileService,
+		stateStore:            stateStore,
+		publisher:             publisher,
+		outboxWake:            make(chan struct{}, 1),
 		userStates:            make(map[int64]*UserState),
 		telegramProviderToken: telegramProviderToken,
+		tariffs:               tariffs,
 	}
 }
 
-func (b *BotService) Start() {
+func (b *BotService) Start(incoming <-chan events.Event) {
+	b.restoreStates()
+
 	u := tgbotapi.NewUpdate(0)
 	u.Timeout = 60
 	updates := b.botAPI.GetUpdatesChan(u)
 
-	for update := range updates {
-		if update.PreCheckoutQuery != nil {
-			b.handlePreCheckoutQuery(update.PreCheckoutQuery)
-			continue
-		}
+	// События из бота администратора обрабатываются в том же цикле, что и апдейты,
+	// поэтому userStates не нужно защищать мьютексом
+	for {
+		select {
+		case update, ok := <-updates:
+			if !ok {
+				return
+			}
+			b.handleUpdate(update)
 
-		if update.Message != nil && update.Message.SuccessfulPayment != nil {
-			b.handleSuccessfulPayment(update.Message)
-			continue
+		case event, ok := <-incoming:
+			if !ok {
+				incoming = nil
+				continue
+			}
+			b.handleEvent(event)
 		}
+	}
+}
 
-		if update.Message != nil && update.Message.Text == "Подробнее о привилегиях" {
-			b.handlePrivilegesInfo(update.Message.Chat.ID)
-			continue
-		}
+// Восстанавливаем незавершённые регистрации после перезапуска
+func (b *BotService) restoreStates() {
+	states, err := b.stateStore.LoadAll()
+	if err != nil {
+		log.Printf("failed to restore user states: %v", err)
+		return
+	}
 
-		if update.Message == nil {
-			continue
-		}
+	b.userStates = states
+	log.Printf("Restored %d user states", len(states))
+}
 
-		chatID := update.Message.Chat.ID
-		text := update.Message.Text
-
-		// Инициализируем state для нового юзера
-		if _, exists := b.userStates[chatID]; !exists {
-			req, err := b.registrationRepo.GetLatestByTelegramUserID(chatID)
-			if err == nil && req != nil {
-				switch req.Status {
-				case "approved":
-					b.userStates[chatID] = &UserState{Step: "awaiting_payment"}
-				case "needs_revision":
-					b.userStates[chatID] = &UserState{Step: "needs_revision", RequestID: req.ID}
-				default:
-					b.userStates[chatID] = &UserState{Step: "start"}
-				}
-			} else {
-				b.userStates[chatID] = &UserState{Step: "start"}
-			}
+// Сохраняем состояние чата после каждого перехода
+func (b *BotService) persistState(chatID int64) {
+	state, exists := b.userStates[chatID]
+	if !exists {
+		if err := b.stateStore.Delete(chatID); err != nil {
+			log.Printf("failed to delete state for chatID %d: %v", chatID, err)
 		}
+		return
+	}
 
-		state := b.userStates[chatID]
+	if err := b.stateStore.Save(chatID, state); err != nil {
+		log.Printf("failed to save state for chatID %d: %v", chatID, err)
+	}
+}
 
-		// Главное меню
-		if state.Step == "start" {
-			if text == "Начать регистрацию" {
-				b.handleStartButton(chatID)
-				continue
-			} else if text == "Написать админу" && b.hasRegistrationRequest(chatID) {
-				b.handleWriteAdmin(chatID)
-				continue
-			} else if text == "Написать админу" && !b.hasRegistrationRequest(chatID) {
-				msg := tgbotapi.NewMessage(chatID, "Вы сможете написать админу после отправки заявки.")
-				b.botAPI.Send(msg)
-				continue
-			}
+func (b *BotService) handleUpdate(update tgbotapi.Update) {
+	if update.CallbackQuery != nil {
+		switch {
+		case strings.HasPrefix(update.CallbackQuery.Data, catalogCallbackPrefix):
+			b.handleCatalogCallback(update.CallbackQuery)
+		case strings.HasPrefix(update.CallbackQuery.Data, supportCallbackPrefix):
+			b.handleSupportCallback(update.CallbackQuery)
 		}
+		return
+	}
+
+	if update.PreCheckoutQuery != nil {
+		b.handlePreCheckoutQuery(update.PreCheckoutQuery)
+		return
+	}
+
+	if update.Message != nil && update.Message.SuccessfulPayment != nil {
+		b.handleSuccessfulPayment(update.Message)
+		return
+	}
+
+	if update.Message != nil && update.Message.Text == "Подробнее о привилегиях" {
+		b.handlePrivilegesInfo(update.Message.Chat.ID)
+		return
+	}
+
+	if update.Message != nil && update.Message.Text == renewButton {
+		b.handleRenewal(update.Message.Chat.ID)
+		return
+	}
+
+	if update.Message == nil {
+		return
+	}
+
+	chatID := update.Message.Chat.ID
+	text := update.Message.Text
 
-		switch state.Step {
-		case "start":
-			b.handleStartState(chatID)
-		case "first_name":
-			b.handleFirstName(chatID, text)
-		case "last_name":
-			b.handleLastName(chatID, text)
-		case "birth_date":
-			b.handleBirthDate(chatID, text)
-		case "user_status":
-			b.handleUserStatus(chatID, text)
-		case "document":
-			b.handleDocument(chatID, update.Message)
-		case "phone_number":
-			b.handlePhoneNumber(chatID, text)
-		case "agreement":
-			b.handleAgreement(chatID, text, update.Message.From.ID)
-		case "write_admin":
-			b.handleWriteAdminMessage(chatID, update.Message)
-		case "awaiting_payment":
-			b.handlePayment(chatID, text, b.telegramProviderToken)
-		case "waiting_payment_confirmation":
-			msg := tgbotapi.NewMessage(chatID, "Платеж уже инициирован. Пожалуйста, завершите оплату в Telegram")
+	defer b.persistState(chatID)
+
+	// Инициализируем state для нового юзера, а из главного меню
+	// подхватываем изменения статуса заявки
+	if state, exists := b.userStates[chatID]; !exists || state.Step == "start" {
+		b.userStates[chatID] = b.initialState(chatID)
+	}
+
+	state := b.userStates[chatID]
+
+	// Главное меню
+	if state.Step == "start" {
+		if text == "Начать регистрацию" {
+			b.handleStartButton(chatID)
+			return
+		} else if text == "Написать админу" && b.hasRegistrationRequest(chatID) {
+			b.handleWriteAdmin(chatID)
+			return
+		} else if text == "Написать админу" && !b.hasRegistrationRequest(chatID) {
+			msg := tgbotapi.NewMessage(chatID, "Вы сможете написать админу после отправки заявки.")
 			b.botAPI.Send(msg)
-		default:
-			log.Printf("Unknown state %s for chatID %d", state.Step, chatID)
+			return
 		}
 	}
+
+	switch state.Step {
+	case "start":
+		b.handleStartState(chatID)
+	case "first_name":
+		b.handleFirstName(chatID, text)
+	case "last_name":
+		b.handleLastName(chatID, text)
+	case "birth_date":
+		b.handleBirthDate(chatID, text)
+	case "user_status":
+		b.handleUserStatus(chatID, text)
+	case "document":
+		b.handleDocument(chatID, update.Message)
+	case "phone_number":
+		b.handlePhoneNumber(chatID, text)
+	case "agreement":
+		b.handleAgreement(chatID, text, update.Message.From.ID)
+	case "write_admin":
+		b.handleWriteAdminMessage(chatID, update.Message)
+	case "needs_revision":
+		b.handleNeedsRevision(chatID, text)
+	case "revision_document":
+		b.handleRevisionDocument(chatID, update.Message)
+	case "revision_review":
+		b.handleRevisionReview(chatID, text)
+	case "revision_edit":
+		b.handleRevisionEdit(chatID, text)
+	case "awaiting_payment":
+		b.handlePayment(chatID, text)
+	case "waiting_payment_confirmation":
+		msg := tgbotapi.NewMessage(chatID, "Платеж уже инициирован. Пожалуйста, завершите оплату в Telegram")
+		b.botAPI.Send(msg)
+	default:
+		log.Printf("Unknown state %s for chatID %d", state.Step, chatID)
+	}
+}
+
+func (b *BotService) initialState(chatID int64) *UserState {
+	if _, err := b.usersRepo.GetByTelegramUserID(chatID); err == nil {
+		return &UserState{Step: "start"}
+	}
+
+	req, err := b.registrationRepo.GetLatestByTelegramUserID(chatID)
+	if err != nil || req == nil {
+		return &UserState{Step: "start"}
+	}
+
+	switch req.Status {
+	case lifecycle.Approved:
+		return &UserState{Step: "awaiting_payment"}
+	case lifecycle.NeedsRevision:
+		return &UserState{Step: "needs_revision", RequestID: req.ID}
+	default:
+		return &UserState{Step: "start"}
+	}
 }
 
 func (b *BotService) handleStartState(chatID int64) {
@@ -192,65 +301,66 @@ func (b *BotService) handlePreCheckoutQuery(query *tgbotapi.PreCheckoutQuery) {
 		OK:                 true,
 	}
 
+	if reason := b.validatePreCheckout(query); reason != "" {
+		log.Printf("rejecting PreCheckoutQuery %s from %d: %s", query.ID, query.From.ID, reason)
+		confirm.OK = false
+		confirm.ErrorMessage = reason
+	}
+
 	if _, err := b.botAPI.Request(confirm); err != nil {
 		log.Printf("failed to confirm PrecheckoutQuery: %v", err)
 	}
 }
 
-func (b *BotService) handlePrivilegesInfo(chatId int64) {
-	log.Printf("sending privileges (media group) пользователю %d", chatId)
-
-	files, err := os.ReadDir("privileges")
-	if err != nil {
-		log.Printf("failed to read /privileges: %v", err)
-		msg := tgbotapi.NewMessage(chatId, "Не удалось загрузить привилегии. Попробуйте позже")
-		b.botAPI.Send(msg)
-		return
+// Проверка инвойса перед списанием денег. Возвращает причину отказа
+// для пользователя или пустую строку, если оплату можно принять
+func (b *BotService) validatePreCheckout(query *tgbotapi.PreCheckoutQuery) string {
+	tariff, chatID, ok := b.parsePayload(query.InvoicePayload)
+	if !ok || chatID != query.From.ID {
+		return "Счёт недействителен. Пожалуйста, запросите новый счёт в боте."
 	}
 
-	var media []interface{}
-	count := 0
-
-	for _, file := range files {
-		if file.IsDir() {
-			continue
-		}
-
-		if !strings.HasSuffix(file.Name(), ".jpg") &&
-			!strings.HasSuffix(file.Name(), ".png") &&
-			!strings.HasSuffix(file.Name(), ".jpeg") {
-			continue
-		}
+	if query.TotalAmount != tariff.Amount || query.Currency != tariff.Currency {
+		return "Сумма счёта устарела. Пожалуйста, запросите новый счёт в боте."
+	}
 
-		if count >= 10 {
-			break
-		}
+	user, err := b.usersRepo.GetByTelegramUserID(chatID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.Printf("validatePreCheckout: %v", err)
+		return "Не удалось проверить оплату. Попробуйте позже."
+	}
 
-		photo := tgbotapi.NewInputMediaPhoto(tgbotapi.FilePath(filepath.Join("privileges", file.Name())))
-		if count == 0 {
-			photo.Caption = "Вот привилегии Ambassador card:"
+	if tariff.Code == TariffRenewal {
+		if user == nil {
+			return "Продление доступно только участникам сообщества."
 		}
+		return ""
+	}
 
-		media = append(media, photo)
-		count++
+	if user != nil {
+		return "Вы уже участник сообщества. Для продления подписки нажмите «Продлить»."
 	}
 
-	if len(media) == 0 {
-		msg := tgbotapi.NewMessage(chatId, "Пока нет доступных изображений")
-		b.botAPI.Send(msg)
-		return
+	req, err := b.registrationRepo.GetLatestByTelegramUserID(chatID)
+	if err != nil {
+		log.Printf("validatePreCheckout: %v", err)
+		return "Заявка на регистрацию не найдена."
 	}
 
-	mediaGroup := tgbotapi.MediaGroupConfig{
-		ChatID: chatId,
-		Media:  media,
+	if req.Status != lifecycle.Approved {
+		return "Оплата доступна только после одобрения заявки."
 	}
 
-	if _, err := b.botAPI.SendMediaGroup(mediaGroup); err != nil {
-		log.Printf("failed to send media group: %v", err)
-		msg := tgbotapi.NewMessage(chatId, "Ошибка при отправке изображений. Попробуйте позже")
-		b.botAPI.Send(msg)
+	_, err = b.usersRepo.GetByPhoneNumber(req.PhoneNumber)
+	if err == nil {
+		return "Этот номер телефона уже зарегистрирован. Пожалуйста, напишите администратору."
 	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		log.Printf("validatePreCheckout: %v", err)
+		return "Не удалось проверить оплату. Попробуйте позже."
+	}
+
+	return ""
 }
 
 func (b *BotService) handleFirstName(chatID int64, firstName string) {
@@ -304,15 +414,8 @@ func (b *BotService) handleBirthDate(chatID int64, date string) {
 	b.botAPI.Send(msg)
 }
 
-func (b *BotService) handleUserStatus(chatID int64, status string) {
-	textLower := NormalizeText(status)
-	statusMap := map[string]string{
-		"студент":   "student",
-		"сотрудник": "employee",
-		"выпускник": "graduate",
-	}
-
-	status, ok := statusMap[textLower]
+func (b *BotService) handleUserStatus(chatID int64, text string) {
+	status, ok := ParseUserStatus(text)
 	if !ok {
 		msg := tgbotapi.NewMessage(chatID, "Пожалуйста, выберите один из вариантов: Студент, Сотрудник, Выпускник")
 		b.botAPI.Send(msg)
@@ -322,21 +425,14 @@ func (b *BotService) handleUserStatus(chatID int64, status string) {
 	b.userStates[chatID].UserStatus = status
 	b.userStates[chatID].Step = "document"
 
-	docType := map[string]string{
-		"student":  "▪️студенческого билета\n▪️пропуска",
-		"employee": "▪️пропуска",
-		"graduate": "▪️студенческого билета\n▪️карты выпускника",
-	}
-
-	msg := tgbotapi.NewMessage(chatID,
-		"Пожалуйста, загрузите фото или скан\n"+docType[status]+"\nили любого другого документа, удостоверяющего вашу принадлежность к альма-матер")
+	msg := tgbotapi.NewMessage(chatID, DocumentHint(status))
 	b.botAPI.Send(msg)
 }
 
 func (b *BotService) handlePhoneNumber(chatID int64, text string) {
-	normalized := NormalizePhoneNumber(text)
+	normalized := phone.Normalize(text)
 
-	if !IsValidPhoneNumber(normalized) {
+	if !phone.IsValid(normalized) {
 		msg := tgbotapi.NewMessage(chatID, "Неверный формат номера телефона. Пример: +79991234567")
 		b.botAPI.Send(msg)
 		return
@@ -449,7 +545,9 @@ func (b *BotService) handleAgreement(chatID int64, text string, telegramUserID i
 			PhoneNumber:    state.PhoneNumber,
 		}
 
-		err := b.registrationRepo.Create(pointer.To(req))
+		created := pointer.To(req)
+
+		err := b.registrationRepo.Create(created)
 		if err != nil {
 			log.Printf("failed to create reg request: %v", err)
 			msg := tgbotapi.NewMessage(chatID, "Произошла ошибка при сохранении заявки. Попробуйте позже")
@@ -457,6 +555,8 @@ func (b *BotService) handleAgreement(chatID int64, text string, telegramUserID i
 			return
 		}
 
+		b.publish(events.Event{Type: events.RequestSubmitted, RequestID: created.ID})
+
 		delete(b.userStates, chatID)
 
 		var keyboard [][]tgbotapi.KeyboardButton
@@ -477,67 +577,15 @@ func (b *BotService) handleAgreement(chatID int64, text string, telegramUserID i
 	b.botAPI.Send(msg)
 }
 
-func (b *BotService) handleWriteAdmin(chatID int64) {
-	b.userStates[chatID].Step = "write_admin"
-
-	msg := tgbotapi.NewMessage(chatID, "Введите сообщение для администратора:")
-	msg.ReplyMarkup = tgbotapi.NewReplyKeyboard(
-		tgbotapi.NewKeyboardButtonRow(
-			tgbotapi.NewKeyboardButton("Отмена"),
-		),
-	)
-	b.botAPI.Send(msg)
-}
-
-func (b *BotService) handleWriteAdminMessage(chatID int64, message *tgbotapi.Message) {
-	if message.Text == "Отмена" {
+func (b *BotService) handlePayment(chatID int64, text string) {
+	if text == "Отмена" {
 		b.userStates[chatID] = &UserState{Step: "start"}
 		b.handleStartState(chatID)
 		return
 	}
 
-	if message.Text == "" {
-		msg := tgbotapi.NewMessage(chatID, "Сообщение не может быть пустым. Введите текст:")
-		b.botAPI.Send(msg)
-		return
-	}
-
-	req, err := b.registrationRepo.GetLatestByTelegramUserID(chatID)
-	if err != nil {
-		log.Printf("Error fetching user request for admin message: %v", err)
-		msg := tgbotapi.NewMessage(chatID, "Ошибка: у вас нет активной заявки. Сначала подайте заявку.")
-		b.botAPI.Send(msg)
-		b.userStates[chatID] = &UserState{Step: "start"}
-		return
-	}
-
-	err = b.adminRepo.CreateMessage(chatID, req.FirstName, req.LastName, message.Text)
-	if err != nil {
-		log.Printf("Error saving admin message: %v", err)
-		msg := tgbotapi.NewMessage(chatID, "Ошибка при сохранении сообщения. Попробуйте позже.")
-		b.botAPI.Send(msg)
-		return
-	}
-
-	var keyboard [][]tgbotapi.KeyboardButton
-	keyboard = append(keyboard, tgbotapi.NewKeyboardButtonRow(
-		tgbotapi.NewKeyboardButton("Начать регистрацию"),
-	))
-	if b.hasRegistrationRequest(chatID) {
-		keyboard = append(keyboard, tgbotapi.NewKeyboardButtonRow(
-			tgbotapi.NewKeyboardButton("Написать админу"),
-		))
-	}
-
-	msg := tgbotapi.NewMessage(chatID, "Сообщение отправлено администратору.")
-	msg.ReplyMarkup = tgbotapi.NewReplyKeyboard(keyboard...)
-	b.botAPI.Send(msg)
-}
-
-func (b *BotService) handlePayment(chatID int64, text string, telegramProviderToken string) {
-	if text == "Отмена" {
-		b.userStates[chatID] = &UserState{Step: "start"}
-		b.handleStartState(chatID)
+	if text == "Написать админу" {
+		b.handleWriteAdmin(chatID)
 		return
 	}
 
@@ -547,32 +595,7 @@ func (b *BotService) handlePayment(chatID int64, text string, telegramProviderTo
 		return
 	}
 
-	title := "Регистрация AC"
-	description := "Регистрация в программе Ambassador Card"
-	payload := "ac_signup_payload_" + strconv.FormatInt(chatID, 10)
-	currency := "RUB"
-	price := tgbotapi.LabeledPrice{
-		Label:  "Регистрация",
-		Amount: 250000,
-	}
-
-	invoice := tgbotapi.NewInvoice(
-		chatID,
-		title,
-		description,
-		payload,
-		telegramProviderToken,
-		"",
-		currency,
-		[]tgbotapi.LabeledPrice{price},
-	)
-	invoice.NeedName = false
-	invoice.NeedEmail = false
-	invoice.NeedPhoneNumber = false
-	invoice.NeedShippingAddress = false
-	invoice.IsFlexible = false
-
-	if _, err := b.botAPI.Send(invoice); err != nil {
+	if err := b.sendInvoice(chatID, b.tariffs[TariffSignup]); err != nil {
 		log.Printf("failed to send invoice: %v", err)
 		msg := tgbotapi.NewMessage(chatID, "Не удалось отправить счет. Попробуйте позже")
 		b.botAPI.Send(msg)
@@ -589,49 +612,80 @@ func (b *BotService) handleSuccessfulPayment(message *tgbotapi.Message) {
 
 	log.Printf("Успешный платеж от %d, charge_id: %s", chatId, providerChargeId)
 
-	authCode := GenerateAuthCode()
+	tariff, _, ok := b.parsePayload(payment.InvoicePayload)
+
+	record := &db.Payment{
+		TelegramUserID:   chatId,
+		TelegramChargeID: payment.TelegramPaymentChargeID,
+		ProviderChargeID: providerChargeId,
+		Amount:           int64(payment.TotalAmount),
+		Currency:         payment.Currency,
+		Payload:          payment.InvoicePayload,
+		Tariff:           tariff.Code,
+	}
+	if !ok {
+		record.Tariff = TariffUnknown
+	}
+
+	// Деньги уже списаны: сначала записываем платёж, потом разбираемся, к чему он относится
+	err := b.paymentRepo.Record(record)
+	if errors.Is(err, db.ErrDuplicatePayment) && record.UserID != nil {
+		log.Printf("payment %s already processed, skipping", payment.TelegramPaymentChargeID)
+		return
+	}
+	if err != nil && !errors.Is(err, db.ErrDuplicatePayment) {
+		log.Printf("failed to record payment %s (provider %s, %d %s) from %d: %v",
+			payment.TelegramPaymentChargeID, providerChargeId, payment.TotalAmount, payment.Currency, chatId, err)
+		b.sendPaymentProblem(chatId)
+		return
+	}
+
+	if !ok {
+		log.Printf("unknown invoice payload %q from %d, payment %d recorded", payment.InvoicePayload, chatId, record.ID)
+		b.sendPaymentProblem(chatId)
+		return
+	}
 
-	req, err := b.registrationRepo.GetByTelegramID(chatId)
+	if tariff.Code == TariffRenewal {
+		b.handleRenewalPayment(chatId, record, tariff)
+		return
+	}
+
+	req, err := b.registrationRepo.GetLatestByTelegramUserID(chatId)
 	if err != nil {
-		log.Printf("failed to get registration request: %v", err)
+		log.Printf("failed to get registration request for payment %d: %v", record.ID, err)
+		b.sendPaymentProblem(chatId)
 		return
 	}
 
-	now := time.Now()
+	authCode, err := GenerateAuthCode()
+	if err != nil {
+		log.Printf("failed to generate auth code for payment %d: %v", record.ID, err)
+		b.sendPaymentProblem(chatId)
+		return
+	}
 
-	err = b.usersRepo.Create(&db.UserShort{
+	_, err = b.paymentRepo.ApplySignup(record.ID, &db.UserShort{
 		TelegramUserID: chatId,
 		FirstName:      req.FirstName,
 		LastName:       req.LastName,
 		BirthDate:      req.BirthDate,
 		Status:         req.UserStatus,
 		PhoneNumber:    req.PhoneNumber,
-		ExpiresAt:      now.AddDate(0, 1, 0),
-		CreatedAt:      now,
-		UpdatedAt:      now,
-	})
-	if err != nil {
-		log.Printf("failed to create user: %v", err)
+	}, tariff.Months, req.ID, authCode)
+	if errors.Is(err, db.ErrDuplicatePayment) {
+		log.Printf("payment %s already processed, skipping", payment.TelegramPaymentChargeID)
 		return
 	}
-
-	user, err := b.usersRepo.GetByTelegramUserID(chatId)
-	if err != nil {
-		log.Printf("failed to get user by telegram id: %v", err)
-	}
-
-	tokenReq := db.Token{
-		UserID:      user.ID,
-		Token:       nil,
-		Code:        authCode,
-		PhoneNumber: user.PhoneNumber,
+	if errors.Is(err, db.ErrPaymentNotApplied) {
+		log.Printf("signup payment %d from %d recorded without paying request %d: %v", record.ID, chatId, req.ID, err)
+		msg := tgbotapi.NewMessage(chatId, "Оплата получена, но заявка сейчас не ожидает оплаты. Пожалуйста, напишите администратору — платёж сохранён.")
+		b.botAPI.Send(msg)
+		return
 	}
-
-	err = b.tokenRepo.Create(pointer.To(tokenReq))
 	if err != nil {
-		log.Printf("Error creating token: %v", err)
-		msg := tgbotapi.NewMessage(chatId, "Ошибка при создании кода. Попробуйте позже.")
-		b.botAPI.Send(msg)
+		log.Printf("failed to apply signup payment %d: %v", record.ID, err)
+		b.sendPaymentProblem(chatId)
 		return
 	}
 
@@ -662,6 +716,15 @@ func (b *BotService) handleSuccessfulPayment(message *tgbotapi.Message) {
 		),
 	)
 	b.botAPI.Send(msg)
+
+	b.userStates[chatId] = &UserState{Step: "start"}
+	b.persistState(chatId)
+}
+
+// Платёж получен, но применить его автоматически не вышло
+func (b *BotService) sendPaymentProblem(chatID int64) {
+	msg := tgbotapi.NewMessage(chatID, "Оплата получена, но завершить операцию не удалось. Пожалуйста, напишите администратору.")
+	b.botAPI.Send(msg)
 }
 
 func (b *BotService) hasRegistrationRequest(chatID int64) bool {
@@ -0,0 +1,186 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/gratefultolord/ac_signup_bot/internal/db"
+	"github.com/gratefultolord/ac_signup_bot/internal/lifecycle"
+)
+
+const (
+	outboxBatchSize   = 20
+	outboxMaxAttempts = 8
+	outboxBaseBackoff = 30 * time.Second
+	outboxMaxBackoff  = time.Hour
+)
+
+// RunOutboxDispatcher доставляет пользователям уведомления из outbox.
+// Проверяет очередь раз в interval и сразу после wakeOutbox
+func (b *BotService) RunOutboxDispatcher(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		b.dispatchOutbox()
+
+		select {
+		case <-ticker.C:
+		case <-b.outboxWake:
+		}
+	}
+}
+
+// Разбудить диспетчер, не дожидаясь тика
+func (b *BotService) wakeOutbox() {
+	select {
+	case b.outboxWake <- struct{}{}:
+	default:
+	}
+}
+
+func (b *BotService) dispatchOutbox() {
+	for {
+		notifications, err := b.outboxRepo.GetDue(outboxBatchSize)
+		if err != nil {
+			log.Printf("dispatchOutbox: %v", err)
+			return
+		}
+
+		for _, n := range notifications {
+			b.deliverNotification(n)
+		}
+
+		if len(notifications) < outboxBatchSize {
+			return
+		}
+	}
+}
+
+func (b *BotService) deliverNotification(n db.Notification) {
+	err := b.sendNotification(n)
+	if err == nil {
+		if err := b.outboxRepo.MarkSent(n.ID); err != nil {
+			log.Printf("dispatchOutbox: %v", err)
+		}
+		return
+	}
+
+	log.Printf("failed to deliver notification %d to %d: %v", n.ID, n.TelegramUserID, err)
+
+	next := nextAttemptAt(n.Attempts+1, err)
+	if err := b.outboxRepo.MarkFailed(n.ID, err.Error(), next); err != nil {
+		log.Printf("dispatchOutbox: %v", err)
+	}
+}
+
+// Время следующей попытки или nil, если уведомление пора признать недоставляемым.
+// Если пользователь заблокировал бота или Telegram отклонил сам запрос (400),
+// повторять бесполезно
+func nextAttemptAt(attempts int, err error) *time.Time {
+	var tgErr *tgbotapi.Error
+	isTelegramErr := errors.As(err, &tgErr)
+
+	if isTelegramErr && (tgErr.Code == http.StatusForbidden || tgErr.Code == http.StatusBadRequest) {
+		return nil
+	}
+
+	if attempts >= outboxMaxAttempts {
+		return nil
+	}
+
+	delay := outboxBaseBackoff << (attempts - 1)
+	if delay > outboxMaxBackoff {
+		delay = outboxMaxBackoff
+	}
+
+	if isTelegramErr && tgErr.RetryAfter > 0 {
+		delay = time.Duration(tgErr.RetryAfter) * time.Second
+	}
+
+	next := time.Now().Add(delay)
+	return &next
+}
+
+func (b *BotService) sendNotification(n db.Notification) error {
+	chatID := n.TelegramUserID
+
+	switch n.Kind {
+	case db.NotificationSupportReply:
+		return b.sendSupportReply(n)
+
+	case db.NotificationSupportClosed:
+		msg := tgbotapi.NewMessage(chatID, "Ваше обращение закрыто. Если остались вопросы, напишите администратору снова.")
+		_, err := b.botAPI.Send(msg)
+		return err
+	}
+
+	reason := "не указана"
+	if n.Reason != nil {
+		reason = *n.Reason
+	}
+
+	switch lifecycle.Status(n.Kind) {
+	case lifecycle.Approved:
+		approveMessage := "Поздравляем! Ваша заявка одобрена. На языке дипломатии теперь Вы – persona grata. После оплаты вам будет предоставлен доступ в закрытый чат, приложение со специальными условиями от наших лучших партнеров, а также информация о мероприятиях сообщества. Пожалуйста, ознакомьтесь с Публичной офертой.\n"
+
+		publicOffert := "*Доступ в сообщество оплачивается на 1 месяц. Подписка не продлевается автоматически и в любой момент ее можно остановить. "
+		if _, err := b.botAPI.Send(tgbotapi.NewMessage(chatID, approveMessage+publicOffert)); err != nil {
+			return err
+		}
+
+		offertDoc := tgbotapi.NewDocument(chatID, tgbotapi.FilePath("agreements/public.docx"))
+		offertDoc.ReplyMarkup = tgbotapi.NewReplyKeyboard(
+			tgbotapi.NewKeyboardButtonRow(
+				tgbotapi.NewKeyboardButton("Оплатить"),
+				tgbotapi.NewKeyboardButton("Написать админу"),
+			),
+		)
+		_, err := b.botAPI.Send(offertDoc)
+		return err
+
+	case lifecycle.Rejected:
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("Ваша заявка отклонена! Причина: %s", reason))
+		msg.ReplyMarkup = tgbotapi.NewReplyKeyboard(
+			tgbotapi.NewKeyboardButtonRow(
+				tgbotapi.NewKeyboardButton("Написать админу"),
+			),
+		)
+		_, err := b.botAPI.Send(msg)
+		return err
+
+	case lifecycle.NeedsRevision:
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("Ваша заявка требует доработки! Причина: %s", reason))
+		msg.ReplyMarkup = revisionMenu()
+		_, err := b.botAPI.Send(msg)
+		return err
+	}
+
+	return fmt.Errorf("unknown notification kind %q", n.Kind)
+}
+
+func (b *BotService) sendSupportReply(n db.Notification) error {
+	if n.SupportMessageID == nil {
+		return fmt.Errorf("support reply notification %d has no message", n.ID)
+	}
+
+	message, err := b.supportRepo.GetMessage(*n.SupportMessageID)
+	if err != nil {
+		return err
+	}
+
+	reply := tgbotapi.NewMessage(n.TelegramUserID, "Ответ администратора:\n\n"+message.Text)
+	reply.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Ответить", supportReplyCallback),
+		),
+	)
+
+	_, err = b.botAPI.Send(reply)
+	return err
+}
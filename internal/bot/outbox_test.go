@@ -0,0 +1,69 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestNextAttemptAt(t *testing.T) {
+	networkErr := errors.New("connection reset")
+
+	tests := []struct {
+		name     string
+		attempts int
+		err      error
+		want     time.Duration
+		dead     bool
+	}{
+		{name: "first retry", attempts: 1, err: networkErr, want: outboxBaseBackoff},
+		{name: "backoff doubles", attempts: 2, err: networkErr, want: 2 * outboxBaseBackoff},
+		{name: "fourth retry", attempts: 4, err: networkErr, want: 8 * outboxBaseBackoff},
+		{name: "last retry", attempts: outboxMaxAttempts - 1, err: networkErr, want: 64 * outboxBaseBackoff},
+		{name: "attempts exhausted", attempts: outboxMaxAttempts, err: networkErr, dead: true},
+		{name: "bot blocked", attempts: 1, err: &tgbotapi.Error{Code: http.StatusForbidden, Message: "Forbidden: bot was blocked by the user"}, dead: true},
+		{name: "wrapped bot blocked", attempts: 1, err: fmt.Errorf("send: %w", &tgbotapi.Error{Code: http.StatusForbidden}), dead: true},
+		{
+			name:     "retry after from telegram",
+			attempts: 1,
+			err:      &tgbotapi.Error{Code: http.StatusTooManyRequests, ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: 7}},
+			want:     7 * time.Second,
+		},
+		{
+			name:     "retry after beats cap",
+			attempts: outboxMaxAttempts - 1,
+			err:      &tgbotapi.Error{Code: http.StatusTooManyRequests, ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: 7200}},
+			want:     2 * time.Hour,
+		},
+		{name: "bad request", attempts: 1, err: &tgbotapi.Error{Code: http.StatusBadRequest, Message: "Bad Request: chat not found"}, dead: true},
+		{name: "wrapped bad request", attempts: 1, err: fmt.Errorf("send: %w", &tgbotapi.Error{Code: http.StatusBadRequest}), dead: true},
+		{name: "telegram server error", attempts: 3, err: &tgbotapi.Error{Code: http.StatusBadGateway}, want: 4 * outboxBaseBackoff},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := time.Now()
+			next := nextAttemptAt(tt.attempts, tt.err)
+			after := time.Now()
+
+			if tt.dead {
+				if next != nil {
+					t.Fatalf("nextAttemptAt() = %v, want nil", *next)
+				}
+				return
+			}
+
+			if next == nil {
+				t.Fatalf("nextAttemptAt() = nil, want delay %v", tt.want)
+			}
+
+			if next.Before(before.Add(tt.want)) || next.After(after.Add(tt.want)) {
+				t.Fatalf("nextAttemptAt() delay = %v, want %v", next.Sub(before), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,322 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/gratefultolord/ac_signup_bot/internal/db"
+	"github.com/gratefultolord/ac_signup_bot/internal/events"
+	"github.com/gratefultolord/ac_signup_bot/internal/lifecycle"
+	"github.com/gratefultolord/ac_signup_bot/internal/phone"
+)
+
+const (
+	revisionSubmitButton = "Отправить заявку"
+	revisionCancelButton = "Отмена"
+)
+
+// Поля заявки, которые пользователь может исправить при доработке
+var revisionFields = map[string]string{
+	"Имя":           "first_name",
+	"Фамилия":       "last_name",
+	"Дата рождения": "birth_date",
+	"Статус":        "user_status",
+	"Телефон":       "phone_number",
+}
+
+func (b *BotService) handleNeedsRevision(chatID int64, text string) {
+	state := b.userStates[chatID]
+
+	req, err := b.registrationRepo.GetLatestByTelegramUserID(chatID)
+	if err != nil || req.Status != lifecycle.NeedsRevision {
+		log.Printf("handleNeedsRevision: no request awaiting revision for chatID %d: %v", chatID, err)
+		b.userStates[chatID] = &UserState{Step: "start"}
+		b.handleStartState(chatID)
+		return
+	}
+
+	switch text {
+	case "Загрузить новый документ":
+		b.userStates[chatID] = &UserState{
+			Step:        "revision_document",
+			RequestID:   req.ID,
+			FirstName:   req.FirstName,
+			LastName:    req.LastName,
+			BirthDate:   req.BirthDate,
+			UserStatus:  req.UserStatus,
+			PhoneNumber: req.PhoneNumber,
+		}
+
+		msg := tgbotapi.NewMessage(chatID, DocumentHint(req.UserStatus))
+		msg.ReplyMarkup = tgbotapi.NewReplyKeyboard(
+			tgbotapi.NewKeyboardButtonRow(
+				tgbotapi.NewKeyboardButton(revisionCancelButton),
+			),
+		)
+		b.botAPI.Send(msg)
+		return
+
+	case "Написать админу":
+		b.handleWriteAdmin(chatID)
+		return
+	}
+
+	state.RequestID = req.ID
+
+	reason := "не указана"
+	if req.RejectionReason != nil && *req.RejectionReason != "" {
+		reason = *req.RejectionReason
+	}
+
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("Ваша заявка требует доработки. Комментарий администратора: %s", reason))
+	msg.ReplyMarkup = revisionMenu()
+	b.botAPI.Send(msg)
+}
+
+func (b *BotService) handleRevisionDocument(chatID int64, message *tgbotapi.Message) {
+	if message.Text == revisionCancelButton {
+		b.cancelRevision(chatID)
+		return
+	}
+
+	var fileID string
+
+	if message.Document != nil {
+		fileID = message.Document.FileID
+	} else if len(message.Photo) > 0 {
+		fileID = message.Photo[len(message.Photo)-1].FileID
+	} else {
+		msg := tgbotapi.NewMessage(chatID, "Пожалуйста, загрузите документ или фото.")
+		b.botAPI.Send(msg)
+		return
+	}
+
+	filePath, err := b.fileService.SaveFile(fileID)
+	if err != nil {
+		log.Printf("Error saving revision file: %v", err)
+		msg := tgbotapi.NewMessage(chatID, "Ошибка при сохранении файла. Попробуйте снова.")
+		b.botAPI.Send(msg)
+		return
+	}
+
+	state := b.userStates[chatID]
+	state.DocumentPath = filePath
+	state.Step = "revision_review"
+
+	b.sendRevisionSummary(chatID)
+}
+
+func (b *BotService) handleRevisionReview(chatID int64, text string) {
+	state := b.userStates[chatID]
+
+	if text == revisionCancelButton {
+		b.cancelRevision(chatID)
+		return
+	}
+
+	if text == revisionSubmitButton {
+		b.submitRevision(chatID)
+		return
+	}
+
+	field, ok := revisionFields[text]
+	if !ok {
+		msg := tgbotapi.NewMessage(chatID, "Пожалуйста, выберите один из вариантов на клавиатуре")
+		msg.ReplyMarkup = revisionReviewMenu()
+		b.botAPI.Send(msg)
+		return
+	}
+
+	state.EditingField = field
+	state.Step = "revision_edit"
+
+	var msg tgbotapi.MessageConfig
+
+	switch field {
+	case "first_name":
+		msg = tgbotapi.NewMessage(chatID, "Укажите Ваше имя")
+	case "last_name":
+		msg = tgbotapi.NewMessage(chatID, "Укажите Вашу фамилию")
+	case "birth_date":
+		msg = tgbotapi.NewMessage(chatID, "Укажите дату рождения в формате ДД.ММ.ГГГГ (например, 01.01.2000)")
+	case "user_status":
+		msg = tgbotapi.NewMessage(chatID, "Выберите ваш статус в MGIMO-family")
+		msg.ReplyMarkup = tgbotapi.NewReplyKeyboard(
+			tgbotapi.NewKeyboardButtonRow(
+				tgbotapi.NewKeyboardButton("Студент"),
+				tgbotapi.NewKeyboardButton("Сотрудник"),
+				tgbotapi.NewKeyboardButton("Выпускник"),
+			),
+		)
+		b.botAPI.Send(msg)
+		return
+	case "phone_number":
+		msg = tgbotapi.NewMessage(chatID, "Укажите Ваш номер телефона")
+	}
+
+	msg.ReplyMarkup = tgbotapi.NewRemoveKeyboard(true)
+	b.botAPI.Send(msg)
+}
+
+func (b *BotService) handleRevisionEdit(chatID int64, text string) {
+	state := b.userStates[chatID]
+
+	switch state.EditingField {
+	case "first_name":
+		if text == "" {
+			b.botAPI.Send(tgbotapi.NewMessage(chatID, "Пожалуйста, укажите Ваше имя"))
+			return
+		}
+		state.FirstName = text
+
+	case "last_name":
+		if text == "" {
+			b.botAPI.Send(tgbotapi.NewMessage(chatID, "Пожалуйста, укажите Вашу фамилию"))
+			return
+		}
+		state.LastName = text
+
+	case "birth_date":
+		parsedDate, ok := IsValidDate(text)
+		if !ok {
+			b.botAPI.Send(tgbotapi.NewMessage(chatID, "Неверный формат. Введите дату рождения в формате ДД.ММ.ГГГГ"))
+			return
+		}
+		state.BirthDate = parsedDate
+
+	case "user_status":
+		status, ok := ParseUserStatus(text)
+		if !ok {
+			b.botAPI.Send(tgbotapi.NewMessage(chatID, "Пожалуйста, выберите один из вариантов: Студент, Сотрудник, Выпускник"))
+			return
+		}
+		state.UserStatus = status
+
+	case "phone_number":
+		normalized := phone.Normalize(text)
+		if !phone.IsValid(normalized) {
+			b.botAPI.Send(tgbotapi.NewMessage(chatID, "Неверный формат номера телефона. Пример: +79991234567"))
+			return
+		}
+		state.PhoneNumber = normalized
+	}
+
+	state.EditingField = ""
+	state.Step = "revision_review"
+
+	b.sendRevisionSummary(chatID)
+}
+
+func (b *BotService) submitRevision(chatID int64) {
+	state := b.userStates[chatID]
+
+	req, err := b.registrationRepo.GetByID(state.RequestID)
+	if err != nil {
+		log.Printf("submitRevision: failed to load request %d: %v", state.RequestID, err)
+		msg := tgbotapi.NewMessage(chatID, "Произошла ошибка при сохранении заявки. Попробуйте позже")
+		b.botAPI.Send(msg)
+		return
+	}
+
+	oldDocumentPath := req.DocumentPath
+
+	resubmitted := &db.RegistrationRequest{
+		ID:             state.RequestID,
+		TelegramUserID: chatID,
+		FirstName:      state.FirstName,
+		LastName:       state.LastName,
+		BirthDate:      state.BirthDate,
+		UserStatus:     state.UserStatus,
+		DocumentPath:   state.DocumentPath,
+		PhoneNumber:    state.PhoneNumber,
+	}
+
+	err = b.registrationRepo.Resubmit(resubmitted)
+	if err != nil {
+		log.Printf("failed to resubmit reg request: %v", err)
+		msg := tgbotapi.NewMessage(chatID, "Произошла ошибка при сохранении заявки. Попробуйте позже")
+		b.botAPI.Send(msg)
+		return
+	}
+
+	b.publish(events.Event{Type: events.RequestSubmitted, RequestID: resubmitted.ID})
+
+	if oldDocumentPath != state.DocumentPath {
+		if err := b.fileService.DeleteFile(oldDocumentPath); err != nil {
+			log.Printf("failed to delete old document %s: %v", oldDocumentPath, err)
+		}
+	}
+
+	delete(b.userStates, chatID)
+
+	msg := tgbotapi.NewMessage(chatID, "Спасибо! Исправленная заявка отправлена на повторную проверку. Обработка займёт до 24 часов.")
+	msg.ReplyMarkup = tgbotapi.NewReplyKeyboard(
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton("Написать админу"),
+		),
+	)
+	b.botAPI.Send(msg)
+}
+
+// Выход из доработки: загруженный, но не отправленный документ удаляем
+func (b *BotService) cancelRevision(chatID int64) {
+	state := b.userStates[chatID]
+
+	if state.DocumentPath != "" {
+		if err := b.fileService.DeleteFile(state.DocumentPath); err != nil {
+			log.Printf("failed to delete unsent document %s: %v", state.DocumentPath, err)
+		}
+	}
+
+	b.userStates[chatID] = &UserState{Step: "needs_revision", RequestID: state.RequestID}
+
+	msg := tgbotapi.NewMessage(chatID, "Доработка отменена. Вы можете вернуться к ней в любой момент.")
+	msg.ReplyMarkup = revisionMenu()
+	b.botAPI.Send(msg)
+}
+
+func (b *BotService) sendRevisionSummary(chatID int64) {
+	state := b.userStates[chatID]
+
+	info := fmt.Sprintf(
+		"Проверьте данные заявки:\nИмя: %s\nФамилия: %s\nДата рождения: %s\nСтатус: %s\nТелефон: %s\n\n"+
+			"Документ загружен. Если нужно, исправьте данные или отправьте заявку.",
+		state.FirstName, state.LastName, state.BirthDate.Format("02.01.2006"),
+		UserStatusTitle(state.UserStatus), state.PhoneNumber,
+	)
+
+	msg := tgbotapi.NewMessage(chatID, info)
+	msg.ReplyMarkup = revisionReviewMenu()
+	b.botAPI.Send(msg)
+}
+
+func revisionMenu() tgbotapi.ReplyKeyboardMarkup {
+	return tgbotapi.NewReplyKeyboard(
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton("Загрузить новый документ"),
+			tgbotapi.NewKeyboardButton("Написать админу"),
+		),
+	)
+}
+
+func revisionReviewMenu() tgbotapi.ReplyKeyboardMarkup {
+	return tgbotapi.NewReplyKeyboard(
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton("Имя"),
+			tgbotapi.NewKeyboardButton("Фамилия"),
+		),
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton("Дата рождения"),
+			tgbotapi.NewKeyboardButton("Статус"),
+			tgbotapi.NewKeyboardButton("Телефон"),
+		),
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton(revisionSubmitButton),
+		),
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton(revisionCancelButton),
+		),
+	)
+}
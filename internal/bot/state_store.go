@@ -0,0 +1,151 @@
+package bot
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/gratefultolord/ac_signup_bot/internal/db"
+)
+
+// StateStore хранит состояние регистрации по chatID между перезапусками бота
+type StateStore interface {
+	Load(chatID int64) (*UserState, error)
+	LoadAll() (map[int64]*UserState, error)
+	Save(chatID int64, state *UserState) error
+	Delete(chatID int64) error
+}
+
+type PostgresStateStore struct {
+	repo *db.UserStateRepository
+}
+
+func NewPostgresStateStore(repo *db.UserStateRepository) *PostgresStateStore {
+	return &PostgresStateStore{
+		repo: repo,
+	}
+}
+
+func (s *PostgresStateStore) Load(chatID int64) (*UserState, error) {
+	record, err := s.repo.Get(chatID)
+	if err != nil {
+		return nil, fmt.Errorf("PostgresStateStore.Load: %w", err)
+	}
+
+	if record == nil {
+		return nil, nil
+	}
+
+	state, err := decodeUserState(record)
+	if err != nil {
+		return nil, fmt.Errorf("PostgresStateStore.Load: %w", err)
+	}
+
+	return state, nil
+}
+
+func (s *PostgresStateStore) LoadAll() (map[int64]*UserState, error) {
+	records, err := s.repo.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("PostgresStateStore.LoadAll: %w", err)
+	}
+
+	states := make(map[int64]*UserState, len(records))
+	for i := range records {
+		state, err := decodeUserState(&records[i])
+		if err != nil {
+			return nil, fmt.Errorf("PostgresStateStore.LoadAll: chatID %d: %w", records[i].ChatID, err)
+		}
+
+		states[records[i].ChatID] = state
+	}
+
+	return states, nil
+}
+
+func (s *PostgresStateStore) Save(chatID int64, state *UserState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("PostgresStateStore.Save: %w", err)
+	}
+
+	if err := s.repo.Save(chatID, state.Step, data); err != nil {
+		return fmt.Errorf("PostgresStateStore.Save: %w", err)
+	}
+
+	return nil
+}
+
+func (s *PostgresStateStore) Delete(chatID int64) error {
+	if err := s.repo.Delete(chatID); err != nil {
+		return fmt.Errorf("PostgresStateStore.Delete: %w", err)
+	}
+
+	return nil
+}
+
+func decodeUserState(record *db.UserStateRecord) (*UserState, error) {
+	var state UserState
+	if err := json.Unmarshal(record.Data, &state); err != nil {
+		return nil, err
+	}
+
+	state.Step = record.Step
+
+	return &state, nil
+}
+
+// MemoryStateStore не переживает перезапуск, используется в тестах и локально
+type MemoryStateStore struct {
+	mu     sync.Mutex
+	states map[int64]UserState
+}
+
+func NewMemoryStateStore() *MemoryStateStore {
+	return &MemoryStateStore{
+		states: make(map[int64]UserState),
+	}
+}
+
+func (s *MemoryStateStore) Load(chatID int64) (*UserState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.states[chatID]
+	if !ok {
+		return nil, nil
+	}
+
+	return &state, nil
+}
+
+func (s *MemoryStateStore) LoadAll() (map[int64]*UserState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	states := make(map[int64]*UserState, len(s.states))
+	for chatID, state := range s.states {
+		state := state
+		states[chatID] = &state
+	}
+
+	return states, nil
+}
+
+func (s *MemoryStateStore) Save(chatID int64, state *UserState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.states[chatID] = *state
+
+	return nil
+}
+
+func (s *MemoryStateStore) Delete(chatID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.states, chatID)
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package bot
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMemoryStateStoreRoundTrip(t *testing.T) {
+	birthDate := time.Date(1999, time.March, 14, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		state UserState
+	}{
+		{name: "first step", state: UserState{Step: "first_name"}},
+		{
+			name: "filled form",
+			state: UserState{
+				Step:         "phone_number",
+				FirstName:    "Иван",
+				LastName:     "Иванов",
+				BirthDate:    birthDate,
+				UserStatus:   "student",
+				DocumentPath: "documents/42.jpg",
+			},
+		},
+		{
+			name: "revision",
+			state: UserState{
+				Step:         "revision_edit",
+				RequestID:    17,
+				EditingField: "last_name",
+			},
+		},
+		{name: "privacy confirmation", state: UserState{Step: "agreement", WaitingForPrivacyConfirmation: true}},
+		{name: "message to admin", state: UserState{Step: "write_admin", MessageDraft: "Здравствуйте"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var store StateStore = NewMemoryStateStore()
+			const chatID = 42
+
+			state := tt.state
+			if err := store.Save(chatID, &state); err != nil {
+				t.Fatalf("Save: %v", err)
+			}
+
+			got, err := store.Load(chatID)
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if got == nil || !reflect.DeepEqual(*got, tt.state) {
+				t.Fatalf("Load = %+v, want %+v", got, tt.state)
+			}
+
+			all, err := store.LoadAll()
+			if err != nil {
+				t.Fatalf("LoadAll: %v", err)
+			}
+			if len(all) != 1 || all[chatID] == nil || !reflect.DeepEqual(*all[chatID], tt.state) {
+				t.Fatalf("LoadAll = %+v, want only chat %d", all, chatID)
+			}
+
+			if err := store.Delete(chatID); err != nil {
+				t.Fatalf("Delete: %v", err)
+			}
+			if got, err := store.Load(chatID); err != nil || got != nil {
+				t.Fatalf("Load after Delete = %+v, %v, want nil", got, err)
+			}
+		})
+	}
+}
+
+func TestMemoryStateStoreMissingChat(t *testing.T) {
+	store := NewMemoryStateStore()
+
+	got, err := store.Load(1)
+	if err != nil || got != nil {
+		t.Fatalf("Load = %+v, %v, want nil", got, err)
+	}
+
+	if err := store.Delete(1); err != nil {
+		t.Fatalf("Delete of missing chat: %v", err)
+	}
+}
+
+func TestMemoryStateStoreCopiesState(t *testing.T) {
+	store := NewMemoryStateStore()
+
+	state := &UserState{Step: "first_name"}
+	if err := store.Save(1, state); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	state.Step = "last_name"
+
+	loaded, err := store.Load(1)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if loaded.Step != "first_name" {
+		t.Fatalf("saved state changed with caller's copy: Step = %q", loaded.Step)
+	}
+
+	loaded.Step = "birth_date"
+	again, err := store.Load(1)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if again.Step != "first_name" {
+		t.Fatalf("saved state changed with loaded copy: Step = %q", again.Step)
+	}
+}
+
+func TestPersistAndRestoreStates(t *testing.T) {
+	store := NewMemoryStateStore()
+	b := &BotService{
+		stateStore: store,
+		userStates: map[int64]*UserState{
+			1: {Step: "last_name", FirstName: "Иван"},
+			2: {Step: "write_admin", MessageDraft: "Вопрос"},
+		},
+	}
+
+	b.persistState(1)
+	b.persistState(2)
+
+	// Завершённая регистрация удаляется из хранилища
+	delete(b.userStates, 2)
+	b.persistState(2)
+
+	restarted := &BotService{stateStore: store}
+	restarted.restoreStates()
+
+	want := map[int64]*UserState{
+		1: {Step: "last_name", FirstName: "Иван"},
+	}
+	if !reflect.DeepEqual(restarted.userStates, want) {
+		t.Fatalf("restored states = %+v, want %+v", restarted.userStates, want)
+	}
+}
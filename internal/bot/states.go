@@ -12,5 +12,6 @@ type UserState struct {
 	PhoneNumber                   string
 	RequestID                     int64
 	MessageDraft                  string
+	EditingField                  string
 	WaitingForPrivacyConfirmation bool
 }
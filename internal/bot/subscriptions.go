@@ -0,0 +1,122 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/gratefultolord/ac_signup_bot/internal/db"
+)
+
+const renewButton = "Продлить"
+
+// За сколько дней до окончания подписки напоминаем, по возрастанию
+var reminderDays = []int{1, 3, 7}
+
+// RunSubscriptionScheduler периодически напоминает об окончании подписки
+// и помечает истёкшими подписки после льготного периода
+func (b *BotService) RunSubscriptionScheduler(interval, gracePeriod time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		b.checkSubscriptions(gracePeriod)
+		<-ticker.C
+	}
+}
+
+func (b *BotService) checkSubscriptions(gracePeriod time.Duration) {
+	for _, days := range reminderDays {
+		users, err := b.subscriptionRepo.GetDueReminders(days)
+		if err != nil {
+			log.Printf("checkSubscriptions: %v", err)
+			continue
+		}
+
+		for _, user := range users {
+			text := fmt.Sprintf(
+				"Ваша подписка Ambassador card заканчивается %s. Чтобы сохранить доступ к привилегиям сообщества, продлите её заранее.",
+				user.ExpiresAt.Format("02.01.2006"),
+			)
+
+			msg := tgbotapi.NewMessage(user.TelegramUserID, text)
+			msg.ReplyMarkup = renewMenu()
+			if _, err := b.botAPI.Send(msg); err != nil {
+				log.Printf("failed to send subscription reminder to %d: %v", user.TelegramUserID, err)
+				continue
+			}
+
+			if err := b.subscriptionRepo.MarkReminderSent(user.ID, user.ExpiresAt, days); err != nil {
+				log.Printf("checkSubscriptions: %v", err)
+			}
+		}
+	}
+
+	expired, err := b.subscriptionRepo.ExpireLapsed(gracePeriod)
+	if err != nil {
+		log.Printf("checkSubscriptions: %v", err)
+		return
+	}
+
+	for _, user := range expired {
+		log.Printf("Membership expired for user %d", user.ID)
+
+		msg := tgbotapi.NewMessage(user.TelegramUserID,
+			"Срок действия Вашей подписки Ambassador card истёк. Вы можете продлить её в любой момент.")
+		msg.ReplyMarkup = renewMenu()
+		b.botAPI.Send(msg)
+	}
+}
+
+func (b *BotService) handleRenewal(chatID int64) {
+	if _, err := b.usersRepo.GetByTelegramUserID(chatID); err != nil {
+		log.Printf("handleRenewal: %v", err)
+		msg := tgbotapi.NewMessage(chatID, "Продление доступно только участникам сообщества.")
+		b.botAPI.Send(msg)
+		return
+	}
+
+	if err := b.sendInvoice(chatID, b.tariffs[TariffRenewal]); err != nil {
+		log.Printf("failed to send renewal invoice: %v", err)
+		msg := tgbotapi.NewMessage(chatID, "Не удалось отправить счет. Попробуйте позже")
+		b.botAPI.Send(msg)
+	}
+}
+
+func (b *BotService) handleRenewalPayment(chatID int64, payment *db.Payment, tariff Tariff) {
+	user, err := b.paymentRepo.ApplyRenewal(payment.ID, chatID, tariff.Months)
+	if errors.Is(err, db.ErrDuplicatePayment) {
+		log.Printf("payment %s already processed, skipping", payment.TelegramChargeID)
+		return
+	}
+	if err != nil {
+		log.Printf("failed to apply renewal payment %d: %v", payment.ID, err)
+		msg := tgbotapi.NewMessage(chatID, "Оплата получена, но продлить подписку не удалось. Пожалуйста, напишите администратору.")
+		b.botAPI.Send(msg)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(
+		"Спасибо! Подписка продлена до %s.", user.ExpiresAt.Format("02.01.2006"),
+	))
+	msg.ReplyMarkup = tgbotapi.NewReplyKeyboard(
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton("Написать админу"),
+		),
+	)
+	b.botAPI.Send(msg)
+}
+
+func renewMenu() tgbotapi.ReplyKeyboardMarkup {
+	return tgbotapi.NewReplyKeyboard(
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton(renewButton),
+		),
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton("Написать админу"),
+		),
+	)
+}
@@ -0,0 +1,159 @@
+package bot
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/gratefultolord/ac_signup_bot/internal/db"
+	"github.com/gratefultolord/ac_signup_bot/internal/events"
+)
+
+const (
+	supportCallbackPrefix = "support"
+	supportReplyCallback  = "support:reply"
+
+	// Сколько последних сообщений обращения показывать пользователю
+	supportHistoryLimit = 10
+
+	// Длинные сообщения в истории обрезаем, чтобы уложиться в лимит Telegram
+	supportHistoryTextLimit = 300
+)
+
+func (b *BotService) handleWriteAdmin(chatID int64) {
+	b.userStates[chatID].Step = "write_admin"
+
+	b.sendSupportHistory(chatID)
+
+	msg := tgbotapi.NewMessage(chatID, "Введите сообщение для администратора:")
+	msg.ReplyMarkup = tgbotapi.NewReplyKeyboard(
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton("Отмена"),
+		),
+	)
+	b.botAPI.Send(msg)
+}
+
+func (b *BotService) handleWriteAdminMessage(chatID int64, message *tgbotapi.Message) {
+	if message.Text == "Отмена" {
+		b.userStates[chatID] = &UserState{Step: "start"}
+		b.handleStartState(chatID)
+		return
+	}
+
+	if message.Text == "" {
+		msg := tgbotapi.NewMessage(chatID, "Сообщение не может быть пустым. Введите текст:")
+		b.botAPI.Send(msg)
+		return
+	}
+
+	req, err := b.registrationRepo.GetLatestByTelegramUserID(chatID)
+	if err != nil || req == nil {
+		log.Printf("Error fetching user request for admin message: %v", err)
+		msg := tgbotapi.NewMessage(chatID, "Ошибка: у вас нет активной заявки. Сначала подайте заявку.")
+		b.botAPI.Send(msg)
+		b.userStates[chatID] = &UserState{Step: "start"}
+		return
+	}
+
+	conversation, saved, err := b.supportRepo.AddUserMessage(chatID, req.FirstName, req.LastName, message.Text)
+	if err != nil {
+		log.Printf("Error saving admin message: %v", err)
+		msg := tgbotapi.NewMessage(chatID, "Ошибка при сохранении сообщения. Попробуйте позже.")
+		b.botAPI.Send(msg)
+		return
+	}
+
+	b.publish(events.Event{Type: events.MessageSent, ConversationID: conversation.ID, MessageID: saved.ID})
+
+	// Возвращаемся в меню, иначе нажатия кнопок уйдут администратору как сообщения
+	b.userStates[chatID] = &UserState{Step: "start"}
+
+	var keyboard [][]tgbotapi.KeyboardButton
+	keyboard = append(keyboard, tgbotapi.NewKeyboardButtonRow(
+		tgbotapi.NewKeyboardButton("Начать регистрацию"),
+	))
+	if b.hasRegistrationRequest(chatID) {
+		keyboard = append(keyboard, tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton("Написать админу"),
+		))
+	}
+
+	msg := tgbotapi.NewMessage(chatID, "Сообщение отправлено администратору. Ответ придёт в этот чат.")
+	msg.ReplyMarkup = tgbotapi.NewReplyKeyboard(keyboard...)
+	b.botAPI.Send(msg)
+}
+
+// Кнопка «Ответить» под ответом администратора
+func (b *BotService) handleSupportCallback(query *tgbotapi.CallbackQuery) {
+	b.botAPI.Request(tgbotapi.NewCallback(query.ID, ""))
+
+	if query.Data != supportReplyCallback {
+		log.Printf("bad support callback %q", query.Data)
+		return
+	}
+
+	chatID := query.From.ID
+
+	defer b.persistState(chatID)
+
+	if _, exists := b.userStates[chatID]; !exists {
+		b.userStates[chatID] = b.initialState(chatID)
+	}
+
+	b.handleWriteAdmin(chatID)
+}
+
+// Показать переписку по незакрытому обращению, если оно есть
+func (b *BotService) sendSupportHistory(chatID int64) {
+	conversation, err := b.supportRepo.GetLatestByTelegramUserID(chatID)
+	if err != nil {
+		log.Printf("failed to load support conversation: %v", err)
+		return
+	}
+
+	if conversation == nil || conversation.Status == db.ConversationClosed {
+		return
+	}
+
+	messages, err := b.supportRepo.GetMessages(conversation.ID, supportHistoryLimit)
+	if err != nil {
+		log.Printf("failed to load support messages: %v", err)
+		return
+	}
+
+	if len(messages) == 0 {
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString("<b>Ваше обращение</b>\n")
+
+	for _, m := range messages {
+		author := "Вы"
+		if m.Sender == db.SenderAdmin {
+			author = "Администратор"
+		}
+
+		sb.WriteString(fmt.Sprintf(
+			"\n<i>%s, %s</i>\n%s\n",
+			author, m.CreatedAt.Format("02.01 15:04"), html.EscapeString(truncateText(m.Text, supportHistoryTextLimit)),
+		))
+	}
+
+	msg := tgbotapi.NewMessage(chatID, sb.String())
+	msg.ParseMode = tgbotapi.ModeHTML
+	b.botAPI.Send(msg)
+}
+
+func truncateText(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+
+	return string(runes[:limit]) + "…"
+}
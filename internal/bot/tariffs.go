@@ -0,0 +1,110 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	TariffSignup  = "signup"
+	TariffRenewal = "renewal"
+
+	// Тариф платежа, payload которого не удалось разобрать. Такой платёж
+	// записывается в журнал, но не применяется
+	TariffUnknown = "unknown"
+
+	signupPayloadPrefix  = "ac_signup_payload_"
+	renewalPayloadPrefix = "ac_renewal_payload_"
+)
+
+type Tariff struct {
+	Code          string
+	Title         string
+	Description   string
+	Label         string
+	PayloadPrefix string
+	Amount        int
+	Currency      string
+	Months        int
+}
+
+// NewTariffs собирает тарифы регистрации и продления по цене подписки
+// в минимальных единицах валюты (копейках)
+func NewTariffs(amount int, currency string) map[string]Tariff {
+	return map[string]Tariff{
+		TariffSignup: {
+			Code:          TariffSignup,
+			Title:         "Регистрация AC",
+			Description:   "Регистрация в программе Ambassador Card",
+			Label:         "Регистрация",
+			PayloadPrefix: signupPayloadPrefix,
+			Amount:        amount,
+			Currency:      currency,
+			Months:        1,
+		},
+		TariffRenewal: {
+			Code:          TariffRenewal,
+			Title:         "Продление AC",
+			Description:   "Продление участия в программе Ambassador Card на 1 месяц",
+			Label:         "Продление",
+			PayloadPrefix: renewalPayloadPrefix,
+			Amount:        amount,
+			Currency:      currency,
+			Months:        1,
+		},
+	}
+}
+
+func (t Tariff) Payload(chatID int64) string {
+	return t.PayloadPrefix + strconv.FormatInt(chatID, 10)
+}
+
+// Определить тариф и chatID по payload инвойса
+func (b *BotService) parsePayload(payload string) (Tariff, int64, bool) {
+	for _, t := range b.tariffs {
+		if !strings.HasPrefix(payload, t.PayloadPrefix) {
+			continue
+		}
+
+		chatID, err := strconv.ParseInt(strings.TrimPrefix(payload, t.PayloadPrefix), 10, 64)
+		if err != nil {
+			return Tariff{}, 0, false
+		}
+
+		return t, chatID, true
+	}
+
+	return Tariff{}, 0, false
+}
+
+func (b *BotService) sendInvoice(chatID int64, tariff Tariff) error {
+	price := tgbotapi.LabeledPrice{
+		Label:  tariff.Label,
+		Amount: tariff.Amount,
+	}
+
+	invoice := tgbotapi.NewInvoice(
+		chatID,
+		tariff.Title,
+		tariff.Description,
+		tariff.Payload(chatID),
+		b.telegramProviderToken,
+		"",
+		tariff.Currency,
+		[]tgbotapi.LabeledPrice{price},
+	)
+	invoice.NeedName = false
+	invoice.NeedEmail = false
+	invoice.NeedPhoneNumber = false
+	invoice.NeedShippingAddress = false
+	invoice.IsFlexible = false
+
+	if _, err := b.botAPI.Send(invoice); err != nil {
+		return fmt.Errorf("sendInvoice %s: %w", tariff.Code, err)
+	}
+
+	return nil
+}
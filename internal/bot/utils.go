@@ -1,8 +1,9 @@
 package bot
 
 import (
+	"crypto/rand"
 	"fmt"
-	"math/rand"
+	"math/big"
 	"regexp"
 	"strings"
 	"time"
@@ -15,20 +16,6 @@ func NormalizeText(text string) string {
 	return text
 }
 
-func NormalizePhoneNumber(raw string) string {
-	digitsOnly := regexp.MustCompile(`\D`).ReplaceAllString(raw, "")
-
-	if strings.HasPrefix(digitsOnly, "8") {
-		digitsOnly = "7" + digitsOnly[1:]
-	}
-
-	if strings.HasPrefix(digitsOnly, "7") && len(digitsOnly) == 11 {
-		return digitsOnly
-	}
-
-	return digitsOnly
-}
-
 func IsValidDate(date string) (time.Time, bool) {
 	matched, _ := regexp.MatchString(`\d{2}\.\d{2}\.\d{4}$`, date)
 	if !matched {
@@ -43,14 +30,49 @@ func IsValidDate(date string) (time.Time, bool) {
 	return parsed, true
 }
 
-func IsValidPhoneNumber(phone string) bool {
-	matched, _ := regexp.MatchString(`^7\d{10}$`, phone)
+func ParseUserStatus(text string) (string, bool) {
+	statusMap := map[string]string{
+		"студент":   "student",
+		"сотрудник": "employee",
+		"выпускник": "graduate",
+	}
+
+	status, ok := statusMap[NormalizeText(text)]
 
-	return matched
+	return status, ok
 }
 
-func GenerateAuthCode() string {
-	rand.Seed(time.Now().UnixNano())
+func UserStatusTitle(status string) string {
+	titles := map[string]string{
+		"student":  "Студент",
+		"employee": "Сотрудник",
+		"graduate": "Выпускник",
+	}
+
+	if title, ok := titles[status]; ok {
+		return title
+	}
+
+	return status
+}
+
+func DocumentHint(status string) string {
+	docType := map[string]string{
+		"student":  "▪️студенческого билета\n▪️пропуска",
+		"employee": "▪️пропуска",
+		"graduate": "▪️студенческого билета\n▪️карты выпускника",
+	}
+
+	return "Пожалуйста, загрузите фото или скан\n" + docType[status] +
+		"\nили любого другого документа, удостоверяющего вашу принадлежность к альма-матер"
+}
+
+// Код входа в приложение обменивается на JWT, поэтому берётся из crypto/rand
+func GenerateAuthCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", fmt.Errorf("GenerateAuthCode: %w", err)
+	}
 
-	return fmt.Sprintf("%06d", rand.Intn(1000000))
+	return fmt.Sprintf("%06d", n.Int64()), nil
 }
@@ -0,0 +1,29 @@
+package bot
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestGenerateAuthCode(t *testing.T) {
+	codeRe := regexp.MustCompile(`^\d{6}$`)
+	seen := make(map[string]bool)
+
+	for i := 0; i < 100; i++ {
+		code, err := GenerateAuthCode()
+		if err != nil {
+			t.Fatalf("GenerateAuthCode: %v", err)
+		}
+
+		if !codeRe.MatchString(code) {
+			t.Fatalf("GenerateAuthCode() = %q, want six digits", code)
+		}
+
+		seen[code] = true
+	}
+
+	// Сто кодов подряд из миллиона вариантов почти никогда не совпадают
+	if len(seen) < 95 {
+		t.Fatalf("GenerateAuthCode() gave %d distinct codes out of 100", len(seen))
+	}
+}
@@ -0,0 +1,90 @@
+package catalog
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/gratefultolord/ac_signup_bot/internal/db"
+)
+
+// PhotoDir — каталог с фотографиями партнёров и категорий
+const PhotoDir = "partner_photos"
+
+// Телеграм ограничивает подпись к фото 1024 символами без учёта разметки
+const captionLimit = 1024
+
+var tagRe = regexp.MustCompile(`<[^>]*>`)
+
+// FormatDiscount возвращает скидку в виде "-15%" или "-500 ₽"
+func FormatDiscount(partner *db.Partner) string {
+	if partner.DiscountType == nil {
+		return ""
+	}
+
+	switch *partner.DiscountType {
+	case db.DiscountPercent:
+		if partner.DiscountPercentSize != nil {
+			return "-" + strconv.FormatFloat(*partner.DiscountPercentSize, 'f', -1, 64) + "%"
+		}
+	case db.DiscountFixed:
+		if partner.DiscountFixedSize != nil {
+			return fmt.Sprintf("-%d ₽", *partner.DiscountFixedSize)
+		}
+	}
+
+	return ""
+}
+
+// FormatPartnerCard — карточка партнёра в HTML, как её видят участники
+func FormatPartnerCard(partner *db.Partner) string {
+	var sb strings.Builder
+
+	sb.WriteString("<b>" + html.EscapeString(partner.Title) + "</b>")
+
+	if discount := FormatDiscount(partner); discount != "" {
+		sb.WriteString("\nСкидка: <b>" + html.EscapeString(discount) + "</b>")
+	}
+
+	if partner.Description != nil && *partner.Description != "" {
+		sb.WriteString("\n\n" + html.EscapeString(*partner.Description))
+	}
+
+	if partner.Address != nil && *partner.Address != "" {
+		sb.WriteString("\n\n📍 " + html.EscapeString(*partner.Address))
+	}
+
+	if partner.URL != nil && *partner.URL != "" {
+		sb.WriteString("\n🔗 " + html.EscapeString(*partner.URL))
+	}
+
+	return sb.String()
+}
+
+// FitsCaption проверяет, поместится ли HTML-карточка в подпись к фото.
+// Телеграм считает длину в UTF-16 после разбора разметки
+func FitsCaption(card string) bool {
+	visible := html.UnescapeString(tagRe.ReplaceAllString(card, ""))
+	return len(utf16.Encode([]rune(visible))) <= captionLimit
+}
+
+// ResolvePhoto находит файл фотографии на диске. В базе хранится либо путь,
+// сохранённый FileService, либо имя файла внутри PhotoDir
+func ResolvePhoto(photoPath *string) string {
+	if photoPath == nil || *photoPath == "" {
+		return ""
+	}
+
+	for _, candidate := range []string{*photoPath, filepath.Join(PhotoDir, *photoPath)} {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,48 @@
+package catalog
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gratefultolord/ac_signup_bot/internal/db"
+)
+
+func TestFitsCaption(t *testing.T) {
+	tests := []struct {
+		name string
+		card string
+		want bool
+	}{
+		{name: "short", card: "<b>Кафе</b>\nСкидка: <b>-10%</b>", want: true},
+		{name: "exactly at limit", card: strings.Repeat("я", captionLimit), want: true},
+		{name: "over limit", card: strings.Repeat("я", captionLimit+1), want: false},
+		{name: "markup is not counted", card: "<b>" + strings.Repeat("a", captionLimit) + "</b>", want: true},
+		{name: "entities count as one character", card: strings.Repeat("&amp;", captionLimit), want: true},
+		{name: "emoji take two UTF-16 units", card: strings.Repeat("📍", captionLimit/2+1), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FitsCaption(tt.card); got != tt.want {
+				t.Fatalf("FitsCaption() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFitsCaptionLongPartnerCard(t *testing.T) {
+	description := strings.Repeat("о", 700)
+	url := "https://example.com/" + strings.Repeat("p", 480)
+	address := "Москва, " + strings.Repeat("у", 200)
+
+	card := FormatPartnerCard(&db.Partner{
+		Title:       "Партнёр",
+		Description: &description,
+		URL:         &url,
+		Address:     &address,
+	})
+
+	if FitsCaption(card) {
+		t.Fatalf("FitsCaption() = true for a %d-character card, want false", len([]rune(card)))
+	}
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// LoadAuthAPI загружает настройки HTTP API авторизации приложения.
+// Токены ботов для него не нужны
+func LoadAuthAPI() (*Config, error) {
+	cfg, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.AuthAPIAddr = os.Getenv("AUTH_API_ADDR")
+	if cfg.AuthAPIAddr == "" {
+		cfg.AuthAPIAddr = ":8080"
+	}
+
+	cfg.JWTAlgorithm = os.Getenv("JWT_ALGORITHM")
+	if cfg.JWTAlgorithm == "" {
+		cfg.JWTAlgorithm = "HS256"
+	}
+
+	switch cfg.JWTAlgorithm {
+	case "HS256":
+		cfg.JWTSecret = os.Getenv("JWT_SECRET")
+		if cfg.JWTSecret == "" {
+			return nil, fmt.Errorf("config.LoadAuthAPI: JWT_SECRET is required for HS256")
+		}
+	case "RS256":
+		cfg.JWTPrivateKeyPath = os.Getenv("JWT_PRIVATE_KEY_PATH")
+		cfg.JWTPublicKeyPath = os.Getenv("JWT_PUBLIC_KEY_PATH")
+		if cfg.JWTPrivateKeyPath == "" || cfg.JWTPublicKeyPath == "" {
+			return nil, fmt.Errorf("config.LoadAuthAPI: JWT_PRIVATE_KEY_PATH and JWT_PUBLIC_KEY_PATH are required for RS256")
+		}
+	default:
+		return nil, fmt.Errorf("config.LoadAuthAPI: unsupported JWT_ALGORITHM %q", cfg.JWTAlgorithm)
+	}
+
+	cfg.JWTTTL = 24 * time.Hour
+	if raw := os.Getenv("JWT_TTL"); raw != "" {
+		cfg.JWTTTL, err = time.ParseDuration(raw)
+		if err != nil || cfg.JWTTTL <= 0 {
+			return nil, fmt.Errorf("config.LoadAuthAPI: JWT_TTL must be a positive duration, e.g. 24h")
+		}
+	}
+
+	cfg.AuthMaxAttempts = 5
+	if raw := os.Getenv("AUTH_MAX_ATTEMPTS"); raw != "" {
+		cfg.AuthMaxAttempts, err = strconv.Atoi(raw)
+		if err != nil || cfg.AuthMaxAttempts <= 0 {
+			return nil, fmt.Errorf("config.LoadAuthAPI: AUTH_MAX_ATTEMPTS must be a positive integer")
+		}
+	}
+
+	cfg.AuthLockout = 15 * time.Minute
+	if raw := os.Getenv("AUTH_LOCKOUT"); raw != "" {
+		cfg.AuthLockout, err = time.ParseDuration(raw)
+		if err != nil || cfg.AuthLockout <= 0 {
+			return nil, fmt.Errorf("config.LoadAuthAPI: AUTH_LOCKOUT must be a positive duration, e.g. 15m")
+		}
+	}
+
+	return cfg, nil
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -17,9 +19,69 @@ type Config struct {
 	DBName                string
 	DBHost                string
 	DBPort                string
+	SubscriptionGrace     time.Duration
+	SubscriptionPrice     int
+	SubscriptionCurrency  string
+	AdminNotifyWindow     time.Duration
+	RequestClaimTTL       time.Duration
+	AdminInviteTTL        time.Duration
+
+	AuthAPIAddr       string
+	JWTAlgorithm      string
+	JWTSecret         string
+	JWTPrivateKeyPath string
+	JWTPublicKeyPath  string
+	JWTTTL            time.Duration
+	AuthMaxAttempts   int
+	AuthLockout       time.Duration
 }
 
 func Load() (*Config, error) {
+	cfg, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.AdminBotToken == "" {
+		return nil, fmt.Errorf("config.Load: ADMIN_BOT_TOKEN is required")
+	}
+
+	if cfg.BotToken == "" {
+		return nil, fmt.Errorf("config.Load: BOT_TOKEN is required")
+	}
+
+	// Уведомления, пришедшие в течение окна, отправляются админам одной сводкой
+	cfg.AdminNotifyWindow = 10 * time.Second
+	if raw := os.Getenv("ADMIN_NOTIFY_WINDOW"); raw != "" {
+		cfg.AdminNotifyWindow, err = time.ParseDuration(raw)
+		if err != nil || cfg.AdminNotifyWindow < 0 {
+			return nil, fmt.Errorf("config.Load: ADMIN_NOTIFY_WINDOW must be a non-negative duration, e.g. 10s")
+		}
+	}
+
+	// Через сколько закрепление заявки за админом перестаёт мешать другим
+	cfg.RequestClaimTTL = 15 * time.Minute
+	if raw := os.Getenv("REQUEST_CLAIM_TTL"); raw != "" {
+		cfg.RequestClaimTTL, err = time.ParseDuration(raw)
+		if err != nil || cfg.RequestClaimTTL <= 0 {
+			return nil, fmt.Errorf("config.Load: REQUEST_CLAIM_TTL must be a positive duration, e.g. 15m")
+		}
+	}
+
+	// Сколько действует ссылка-приглашение нового админа
+	cfg.AdminInviteTTL = 24 * time.Hour
+	if raw := os.Getenv("ADMIN_INVITE_TTL"); raw != "" {
+		cfg.AdminInviteTTL, err = time.ParseDuration(raw)
+		if err != nil || cfg.AdminInviteTTL <= 0 {
+			return nil, fmt.Errorf("config.Load: ADMIN_INVITE_TTL must be a positive duration, e.g. 24h")
+		}
+	}
+
+	return cfg, nil
+}
+
+// Общие для всех сервисов настройки: база данных и подписка
+func load() (*Config, error) {
 	err := godotenv.Load()
 	if err != nil {
 		log.Printf("config.Load: no .env file found - using env variables")
@@ -36,14 +98,6 @@ func Load() (*Config, error) {
 		DBPort:                os.Getenv("DB_PORT"),
 	}
 
-	if cfg.AdminBotToken == "" {
-		return nil, fmt.Errorf("config.Load: ADMIN_BOT_TOKEN is required")
-	}
-
-	if cfg.BotToken == "" {
-		return nil, fmt.Errorf("config.Load: BOT_TOKEN is required")
-	}
-
 	if cfg.DBUser == "" || cfg.DBPassword == "" || cfg.DBName == "" {
 		return nil, fmt.Errorf("config.Load: DB_USER, DB_PASSWORD, DB_NAME are required")
 	}
@@ -56,5 +110,27 @@ func Load() (*Config, error) {
 		cfg.DBPort = "5432"
 	}
 
+	graceDays := 3
+	if raw := os.Getenv("SUBSCRIPTION_GRACE_DAYS"); raw != "" {
+		graceDays, err = strconv.Atoi(raw)
+		if err != nil || graceDays < 0 {
+			return nil, fmt.Errorf("config.Load: SUBSCRIPTION_GRACE_DAYS must be a non-negative integer")
+		}
+	}
+	cfg.SubscriptionGrace = time.Duration(graceDays) * 24 * time.Hour
+
+	cfg.SubscriptionPrice = 250000
+	if raw := os.Getenv("SUBSCRIPTION_PRICE"); raw != "" {
+		cfg.SubscriptionPrice, err = strconv.Atoi(raw)
+		if err != nil || cfg.SubscriptionPrice <= 0 {
+			return nil, fmt.Errorf("config.Load: SUBSCRIPTION_PRICE must be a positive amount in kopecks")
+		}
+	}
+
+	cfg.SubscriptionCurrency = os.Getenv("SUBSCRIPTION_CURRENCY")
+	if cfg.SubscriptionCurrency == "" {
+		cfg.SubscriptionCurrency = "RUB"
+	}
+
 	return cfg, nil
 }
@@ -0,0 +1,142 @@
+package db
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gratefultolord/ac_signup_bot/internal/access"
+)
+
+var (
+	// ErrInviteInvalid — приглашения нет, оно уже использовано или истекло
+	ErrInviteInvalid = errors.New("admin invite is invalid or expired")
+	ErrAlreadyAdmin  = errors.New("user is already an admin")
+)
+
+// AdminInvite — одноразовое приглашение стать админом с заранее выбранной ролью
+type AdminInvite struct {
+	ID           int64       `db:"id"`
+	TokenHash    string      `db:"token_hash"`
+	Role         access.Role `db:"role"`
+	InvitedBy    int64       `db:"invited_by"`
+	ExpiresAt    time.Time   `db:"expires_at"`
+	UsedByChatID *int64      `db:"used_by_chat_id"`
+	UsedAt       *time.Time  `db:"used_at"`
+	CreatedAt    time.Time   `db:"created_at"`
+}
+
+func hashInviteToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Создать приглашение по токену из ссылки; в базе остаётся только его хеш
+func (r *AdminRepository) CreateInvite(token string, role access.Role, invitedBy int64, ttl time.Duration) (*AdminInvite, error) {
+	if !role.Valid() {
+		return nil, fmt.Errorf("AdminRepository.CreateInvite: %w: unknown role %q", ErrValidation, role)
+	}
+
+	var invite AdminInvite
+
+	err := r.db.Get(&invite, `
+	    INSERT INTO admin_invites (token_hash, role, invited_by, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING *
+	`, hashInviteToken(token), role, invitedBy, time.Now().Add(ttl))
+
+	if err != nil {
+		return nil, fmt.Errorf("AdminRepository.CreateInvite: %w", err)
+	}
+
+	return &invite, nil
+}
+
+// Неиспользованные и неистёкшие приглашения
+func (r *AdminRepository) ListInvites() ([]AdminInvite, error) {
+	var invites []AdminInvite
+
+	err := r.db.Select(&invites, `
+	    SELECT * FROM admin_invites
+		WHERE used_at IS NULL AND expires_at > $1
+		ORDER BY created_at, id
+	`, time.Now())
+
+	if err != nil {
+		return nil, fmt.Errorf("AdminRepository.ListInvites: %w", err)
+	}
+
+	return invites, nil
+}
+
+// Отозвать приглашение, пока им не воспользовались
+func (r *AdminRepository) RevokeInvite(inviteID int64) error {
+	_, err := r.db.Exec(`
+	    DELETE FROM admin_invites
+		WHERE id = $1 AND used_at IS NULL
+	`, inviteID)
+
+	if err != nil {
+		return fmt.Errorf("AdminRepository.RevokeInvite: %w", err)
+	}
+
+	return nil
+}
+
+// Принять приглашение: добавить chatID в админы с ролью из приглашения и
+// погасить его. Приглашение блокируется, поэтому по одной ссылке не войдут двое
+func (r *AdminRepository) AcceptInvite(token string, chatID int64) (*AdminInvite, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("AdminRepository.AcceptInvite: %w", err)
+	}
+	defer tx.Rollback()
+
+	var invite AdminInvite
+
+	err = tx.Get(&invite, `
+	    SELECT * FROM admin_invites
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > $2
+		FOR UPDATE
+	`, hashInviteToken(token), time.Now())
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("AdminRepository.AcceptInvite: %w", ErrInviteInvalid)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("AdminRepository.AcceptInvite: %w", err)
+	}
+
+	result, err := tx.Exec(`
+	    INSERT INTO admins (chat_id, role) VALUES ($1, $2)
+		ON CONFLICT (chat_id) DO NOTHING
+	`, chatID, invite.Role)
+
+	if err != nil {
+		return nil, fmt.Errorf("AdminRepository.AcceptInvite: %w", err)
+	}
+
+	if inserted, err := result.RowsAffected(); err != nil || inserted == 0 {
+		return nil, fmt.Errorf("AdminRepository.AcceptInvite: %w", ErrAlreadyAdmin)
+	}
+
+	err = tx.Get(&invite, `
+	    UPDATE admin_invites
+		SET used_by_chat_id = $1, used_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+		RETURNING *
+	`, chatID, invite.ID)
+
+	if err != nil {
+		return nil, fmt.Errorf("AdminRepository.AcceptInvite: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("AdminRepository.AcceptInvite: %w", err)
+	}
+
+	return &invite, nil
+}
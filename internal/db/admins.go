@@ -1,25 +1,25 @@
 package db
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/jmoiron/sqlx"
+
+	"github.com/gratefultolord/ac_signup_bot/internal/access"
 )
 
-type Admin struct {
-	ID        int64     `db:"id"`
-	ChatID    int64     `db:"chat_id"`
-	CreatedAt time.Time `db:"created_at"`
-}
+// ErrLastOwner возвращается, если изменение оставило бы бота без владельца
+var ErrLastOwner = errors.New("at least one owner must remain")
 
-type AdminMessage struct {
-	ID             int64     `db:"id"`
-	TelegramUserID int64     `db:"telegram_user_id"`
-	FirstName      string    `db:"first_name"`
-	LastName       string    `db:"last_name"`
-	Message        string    `db:"message"`
-	CreatedAt      time.Time `db:"created_at"`
+type Admin struct {
+	ID             int64       `db:"id"`
+	ChatID         int64       `db:"chat_id"`
+	Role           access.Role `db:"role"`
+	NotifyRequests bool        `db:"notify_requests"`
+	NotifyMessages bool        `db:"notify_messages"`
+	CreatedAt      time.Time   `db:"created_at"`
 }
 
 type AdminRepository struct {
@@ -37,6 +37,7 @@ func (r *AdminRepository) GetAll() ([]Admin, error) {
 
 	err := r.db.Select(&admins, `
 	    SELECT * FROM admins
+		ORDER BY id
 	`)
 
 	if err != nil {
@@ -46,11 +47,60 @@ func (r *AdminRepository) GetAll() ([]Admin, error) {
 	return admins, nil
 }
 
-func (r *AdminRepository) Create(chatID int64) error {
+func (r *AdminRepository) GetByID(adminID int64) (*Admin, error) {
+	var admin Admin
+
+	err := r.db.Get(&admin, `
+	    SELECT * FROM admins
+		WHERE id = $1
+	`, adminID)
+
+	if err != nil {
+		return nil, fmt.Errorf("AdminRepository.GetByID: %w", err)
+	}
+
+	return &admin, nil
+}
+
+func (r *AdminRepository) GetByChatID(chatID int64) (*Admin, error) {
+	var admin Admin
+
+	err := r.db.Get(&admin, `
+	    SELECT * FROM admins
+		WHERE chat_id = $1
+	`, chatID)
+
+	if err != nil {
+		return nil, fmt.Errorf("AdminRepository.GetByChatID: %w", err)
+	}
+
+	return &admin, nil
+}
+
+// Включить или выключить уведомления о новых заявках и сообщениях
+func (r *AdminRepository) SetNotifications(chatID int64, requests, messages bool) error {
+	_, err := r.db.Exec(`
+	    UPDATE admins
+		SET notify_requests = $1, notify_messages = $2
+		WHERE chat_id = $3
+	`, requests, messages, chatID)
+
+	if err != nil {
+		return fmt.Errorf("AdminRepository.SetNotifications: %w", err)
+	}
+
+	return nil
+}
+
+func (r *AdminRepository) Create(chatID int64, role access.Role) error {
+	if !role.Valid() {
+		return fmt.Errorf("AdminRepository.Create: %w: unknown role %q", ErrValidation, role)
+	}
+
 	_, err := r.db.Exec(`
-	    INSERT INTO admins (chat_id) VALUES ($1)
+	    INSERT INTO admins (chat_id, role) VALUES ($1, $2)
 		ON CONFLICT (chat_id) DO NOTHING
-	`, chatID)
+	`, chatID, role)
 
 	if err != nil {
 		return fmt.Errorf("AdminRepository.Create: %w", err)
@@ -59,44 +109,104 @@ func (r *AdminRepository) Create(chatID int64) error {
 	return nil
 }
 
-func (r *AdminRepository) CreateMessage(telegramUserID int64, firstName, lastName, message string) error {
-	query := `INSERT INTO admin_messages (telegram_user_id, first_name, last_name, message) VALUES ($1, $2, $3, $4)`
+func (r *AdminRepository) SetRole(adminID int64, role access.Role) error {
+	if !role.Valid() {
+		return fmt.Errorf("AdminRepository.SetRole: %w: unknown role %q", ErrValidation, role)
+	}
 
-	_, err := r.db.Exec(query, telegramUserID, firstName, lastName, message)
+	tx, err := r.db.Beginx()
 	if err != nil {
-		return fmt.Errorf("AdminRepository.CreateMessage: %w", err)
+		return fmt.Errorf("AdminRepository.SetRole: %w", err)
+	}
+	defer tx.Rollback()
+
+	if role != access.Owner {
+		if err := ensureOtherOwner(tx, adminID); err != nil {
+			return fmt.Errorf("AdminRepository.SetRole: %w", err)
+		}
+	}
+
+	_, err = tx.Exec(`
+	    UPDATE admins
+		SET role = $1
+		WHERE id = $2
+	`, role, adminID)
+
+	if err != nil {
+		return fmt.Errorf("AdminRepository.SetRole: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("AdminRepository.SetRole: %w", err)
 	}
 
 	return nil
 }
 
-func (r *AdminRepository) IsAdmin(telegramUserID int64) (bool, error) {
-	var count int
-	query := `SELECT COUNT(*) FROM admins WHERE chat_id = $1`
+// Удалить админа. Закреплённые за ним заявки освобождаются (ON DELETE SET NULL)
+func (r *AdminRepository) Delete(adminID int64) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("AdminRepository.Delete: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := ensureOtherOwner(tx, adminID); err != nil {
+		return fmt.Errorf("AdminRepository.Delete: %w", err)
+	}
+
+	_, err = tx.Exec(`
+	    DELETE FROM admins
+		WHERE id = $1
+	`, adminID)
 
-	err := r.db.Get(&count, query, telegramUserID)
 	if err != nil {
-		return false, fmt.Errorf("AdminRepository.IsAdmin: %w", err)
+		return fmt.Errorf("AdminRepository.Delete: %w", err)
 	}
 
-	return count > 0, nil
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("AdminRepository.Delete: %w", err)
+	}
+
+	return nil
 }
 
-func (r *AdminRepository) GetLatestMessages() ([]AdminMessage, error) {
-	var messages []AdminMessage
+// Проверить, что кроме adminID остаётся хотя бы один владелец. Строки владельцев
+// блокируются, чтобы два владельца не сняли друг друга одновременно
+func ensureOtherOwner(tx *sqlx.Tx, adminID int64) error {
+	var owners []int64
 
-	query := `
-	    SELECT
-		    id, telegram_user_id, first_name, last_name, message, created_at
-		FROM admin_messages
-		ORDER BY created_at DESC
-		LIMIT 20	
-	`
+	err := tx.Select(&owners, `
+	    SELECT id FROM admins
+		WHERE role = $1
+		FOR UPDATE
+	`, access.Owner)
 
-	err := r.db.Select(&messages, query)
 	if err != nil {
-		return nil, fmt.Errorf("AdminRepository.GetLatestMessages: %w", err)
+		return err
+	}
+
+	for _, id := range owners {
+		if id != adminID {
+			return nil
+		}
+	}
+
+	if len(owners) == 0 {
+		return nil
 	}
 
-	return messages, nil
+	return ErrLastOwner
+}
+
+func (r *AdminRepository) IsAdmin(telegramUserID int64) (bool, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM admins WHERE chat_id = $1`
+
+	err := r.db.Get(&count, query, telegramUserID)
+	if err != nil {
+		return false, fmt.Errorf("AdminRepository.IsAdmin: %w", err)
+	}
+
+	return count > 0, nil
 }
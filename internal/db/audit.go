@@ -0,0 +1,162 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Действия админов, которые попадают в журнал
+const (
+	AuditRequestApprove = "request_approve"
+	AuditRequestReject  = "request_reject"
+	AuditRequestRevise  = "request_revise"
+	AuditRequestHold    = "request_hold"
+	AuditRequestResume  = "request_resume"
+	AuditRequestNote    = "request_note"
+	AuditDocumentView   = "document_view"
+	AuditAdminInvite    = "admin_invite"
+	AuditInviteRevoke   = "invite_revoke"
+	AuditAdminJoin      = "admin_join"
+	AuditAdminRole      = "admin_role"
+	AuditAdminRemove    = "admin_remove"
+	AuditReasonCreate   = "reason_create"
+	AuditReasonUpdate   = "reason_update"
+	AuditReasonDelete   = "reason_delete"
+	AuditCatalogChange  = "catalog_change"
+	AuditSupportReply   = "support_reply"
+	AuditSupportClose   = "support_close"
+	AuditOutboxRetry    = "outbox_retry"
+	AuditExport         = "audit_export"
+)
+
+// AuditEntry — запись журнала: кто, что и над чем сделал
+type AuditEntry struct {
+	ID          int64     `db:"id"`
+	AdminChatID int64     `db:"admin_chat_id"`
+	Action      string    `db:"action"`
+	TargetType  *string   `db:"target_type"`
+	TargetID    *int64    `db:"target_id"`
+	Details     *string   `db:"details"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+// AuditFilter — условия выборки журнала. Нулевые поля не ограничивают выборку,
+// Until не включается в период
+type AuditFilter struct {
+	AdminChatID int64
+	Action      string
+	Since       time.Time
+	Until       time.Time
+}
+
+func (f AuditFilter) where() (string, []interface{}) {
+	conditions := []string{"TRUE"}
+	var args []interface{}
+
+	if f.AdminChatID != 0 {
+		args = append(args, f.AdminChatID)
+		conditions = append(conditions, fmt.Sprintf("admin_chat_id = $%d", len(args)))
+	}
+
+	if f.Action != "" {
+		args = append(args, f.Action)
+		conditions = append(conditions, fmt.Sprintf("action = $%d", len(args)))
+	}
+
+	if !f.Since.IsZero() {
+		args = append(args, f.Since)
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+
+	if !f.Until.IsZero() {
+		args = append(args, f.Until)
+		conditions = append(conditions, fmt.Sprintf("created_at < $%d", len(args)))
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+// AuditRepository только добавляет и читает записи: изменять журнал
+// не даёт и триггер в базе
+type AuditRepository struct {
+	db *sqlx.DB
+}
+
+func NewAuditRepository(db *sqlx.DB) *AuditRepository {
+	return &AuditRepository{
+		db: db,
+	}
+}
+
+func (r *AuditRepository) Record(entry AuditEntry) error {
+	_, err := r.db.Exec(`
+	    INSERT INTO admin_audit_log
+		(admin_chat_id, action, target_type, target_id, details)
+		VALUES ($1, $2, $3, $4, $5)
+	`,
+		entry.AdminChatID,
+		entry.Action,
+		entry.TargetType,
+		entry.TargetID,
+		entry.Details,
+	)
+
+	if err != nil {
+		return fmt.Errorf("AuditRepository.Record: %w", err)
+	}
+
+	return nil
+}
+
+// Записи по фильтру, сначала самые новые
+func (r *AuditRepository) List(filter AuditFilter, page Page) ([]AuditEntry, error) {
+	var entries []AuditEntry
+
+	where, args := filter.where()
+	args = append(args, page.Limit, page.Offset)
+
+	err := r.db.Select(&entries, fmt.Sprintf(`
+	    SELECT * FROM admin_audit_log
+		WHERE %s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d OFFSET $%d
+	`, where, len(args)-1, len(args)), args...)
+
+	if err != nil {
+		return nil, fmt.Errorf("AuditRepository.List: %w", err)
+	}
+
+	return entries, nil
+}
+
+func (r *AuditRepository) Count(filter AuditFilter) (int, error) {
+	var count int
+
+	where, args := filter.where()
+
+	err := r.db.Get(&count, `SELECT COUNT(*) FROM admin_audit_log WHERE `+where, args...)
+	if err != nil {
+		return 0, fmt.Errorf("AuditRepository.Count: %w", err)
+	}
+
+	return count, nil
+}
+
+// Админы, у которых есть записи в журнале, включая уже удалённых
+func (r *AuditRepository) ListAdmins() ([]int64, error) {
+	var chatIDs []int64
+
+	err := r.db.Select(&chatIDs, `
+	    SELECT DISTINCT admin_chat_id FROM admin_audit_log
+		ORDER BY admin_chat_id
+	`)
+
+	if err != nil {
+		return nil, fmt.Errorf("AuditRepository.ListAdmins: %w", err)
+	}
+
+	return chatIDs, nil
+}
@@ -0,0 +1,172 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrValidation оборачивает ошибки проверки данных перед записью
+var ErrValidation = errors.New("validation failed")
+
+type Category struct {
+	ID          int64     `db:"id"`
+	Title       string    `db:"title"`
+	PhotoPath   *string   `db:"photo_path"`
+	SortOrder   int       `db:"sort_order"`
+	IsPublished bool      `db:"is_published"`
+	CreatedAt   time.Time `db:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at"`
+}
+
+func (c *Category) Validate() error {
+	if strings.TrimSpace(c.Title) == "" {
+		return fmt.Errorf("%w: category title is required", ErrValidation)
+	}
+
+	return nil
+}
+
+// Page — параметры постраничной выборки
+type Page struct {
+	Limit  int
+	Offset int
+}
+
+type CategoryRepository struct {
+	db *sqlx.DB
+}
+
+func NewCategoryRepository(db *sqlx.DB) *CategoryRepository {
+	return &CategoryRepository{
+		db: db,
+	}
+}
+
+func (r *CategoryRepository) Create(category *Category) error {
+	if err := category.Validate(); err != nil {
+		return fmt.Errorf("CategoryRepository.Create: %w", err)
+	}
+
+	err := r.db.Get(category, `
+	    INSERT INTO categories (title, photo_path, sort_order, is_published)
+		VALUES ($1, $2, $3, $4)
+		RETURNING *
+	`, category.Title, category.PhotoPath, category.SortOrder, category.IsPublished)
+
+	if err != nil {
+		return fmt.Errorf("CategoryRepository.Create: %w", err)
+	}
+
+	return nil
+}
+
+func (r *CategoryRepository) GetByID(categoryID int64) (*Category, error) {
+	var category Category
+
+	err := r.db.Get(&category, `
+	    SELECT * FROM categories
+		WHERE id = $1
+	`, categoryID)
+
+	if err != nil {
+		return nil, fmt.Errorf("CategoryRepository.GetByID: %w", err)
+	}
+
+	return &category, nil
+}
+
+func (r *CategoryRepository) List(page Page) ([]Category, error) {
+	var categories []Category
+
+	err := r.db.Select(&categories, `
+	    SELECT * FROM categories
+		ORDER BY sort_order, title, id
+		LIMIT $1 OFFSET $2
+	`, page.Limit, page.Offset)
+
+	if err != nil {
+		return nil, fmt.Errorf("CategoryRepository.List: %w", err)
+	}
+
+	return categories, nil
+}
+
+// Категории, видимые участникам
+func (r *CategoryRepository) ListPublished(page Page) ([]Category, error) {
+	var categories []Category
+
+	err := r.db.Select(&categories, `
+	    SELECT * FROM categories
+		WHERE is_published
+		ORDER BY sort_order, title, id
+		LIMIT $1 OFFSET $2
+	`, page.Limit, page.Offset)
+
+	if err != nil {
+		return nil, fmt.Errorf("CategoryRepository.ListPublished: %w", err)
+	}
+
+	return categories, nil
+}
+
+func (r *CategoryRepository) Count() (int, error) {
+	var count int
+
+	err := r.db.Get(&count, `SELECT COUNT(*) FROM categories`)
+	if err != nil {
+		return 0, fmt.Errorf("CategoryRepository.Count: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *CategoryRepository) Update(category *Category) error {
+	if err := category.Validate(); err != nil {
+		return fmt.Errorf("CategoryRepository.Update: %w", err)
+	}
+
+	err := r.db.Get(category, `
+	    UPDATE categories
+		SET title = $1, photo_path = $2, sort_order = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $4
+		RETURNING *
+	`, category.Title, category.PhotoPath, category.SortOrder, category.ID)
+
+	if err != nil {
+		return fmt.Errorf("CategoryRepository.Update: %w", err)
+	}
+
+	return nil
+}
+
+func (r *CategoryRepository) SetPublished(categoryID int64, published bool) error {
+	_, err := r.db.Exec(`
+	    UPDATE categories
+		SET is_published = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+	`, published, categoryID)
+
+	if err != nil {
+		return fmt.Errorf("CategoryRepository.SetPublished: %w", err)
+	}
+
+	return nil
+}
+
+// Удалить категорию вместе с её партнёрами (ON DELETE CASCADE)
+func (r *CategoryRepository) Delete(categoryID int64) error {
+	_, err := r.db.Exec(`
+	    DELETE FROM categories
+		WHERE id = $1
+	`, categoryID)
+
+	if err != nil {
+		return fmt.Errorf("CategoryRepository.Delete: %w", err)
+	}
+
+	return nil
+}
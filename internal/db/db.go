@@ -13,12 +13,14 @@ type DB struct {
 	Conn *sqlx.DB
 }
 
-func New(cfg *config.Config) (*DB, error) {
-
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s, dbname=%s sslmode=disable",
+// DSN — строка подключения, общая для пула и LISTEN-соединения
+func DSN(cfg *config.Config) string {
+	return fmt.Sprintf("host=%s port=%s user=%s password=%s, dbname=%s sslmode=disable",
 		cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName)
+}
 
-	dbConn, err := sqlx.Connect("postgres", dsn)
+func New(cfg *config.Config) (*DB, error) {
+	dbConn, err := sqlx.Connect("postgres", DSN(cfg))
 	if err != nil {
 		return nil, fmt.Errorf("db.New: cannot connect to database: %w", err)
 	}
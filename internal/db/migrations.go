@@ -1,49 +1,394 @@
 package db
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
-	"io"
 	"log"
 	"os"
-	"strings"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 )
 
-func RunMigrations(db *sqlx.DB, scriptPaths ...string) error {
-	for _, scriptPath := range scriptPaths {
-		log.Printf("Running migration: %s", scriptPath)
+// Ключ advisory lock, общий для signupbot и adminbot
+const migrationsLockKey = 727_001
 
-		file, err := os.Open(scriptPath)
+var migrationFileRe = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+type Migrator struct {
+	db  *sqlx.DB
+	dir string
+}
+
+func NewMigrator(db *sqlx.DB, dir string) *Migrator {
+	return &Migrator{
+		db:  db,
+		dir: dir,
+	}
+}
+
+// Прочитать миграции из каталога, отсортированные по версии
+func (m *Migrator) Load() ([]Migration, error) {
+	entries, err := os.ReadDir(m.dir)
+	if err != nil {
+		return nil, fmt.Errorf("Migrator.Load: cannot read %s: %w", m.dir, err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		match := migrationFileRe.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Migrator.Load: bad version in %s: %w", entry.Name(), err)
+		}
+
+		content, err := os.ReadFile(filepath.Join(m.dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("Migrator.Load: cannot read %s: %w", entry.Name(), err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("Migrator.Load: version %d has different names: %s and %s", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("Migrator.Load: migration %d_%s has no up script", migration.Version, migration.Name)
+		}
+
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Применить все неприменённые миграции
+func (m *Migrator) Up() error {
+	migrations, err := m.Load()
+	if err != nil {
+		return fmt.Errorf("Migrator.Up: %w", err)
+	}
+
+	return m.withLock(func(conn *sqlx.Conn) error {
+		applied, err := appliedVersions(conn)
 		if err != nil {
-			return fmt.Errorf("cannot open %s: %w", scriptPath, err)
+			return fmt.Errorf("Migrator.Up: %w", err)
+		}
+
+		for _, migration := range migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+
+			if err := applyMigration(conn, migration, true); err != nil {
+				return fmt.Errorf("Migrator.Up: %w", err)
+			}
 		}
-		defer file.Close()
 
-		content, err := io.ReadAll(file)
+		return nil
+	})
+}
+
+// Откатить последние steps применённых миграций
+func (m *Migrator) Down(steps int) error {
+	migrations, err := m.Load()
+	if err != nil {
+		return fmt.Errorf("Migrator.Down: %w", err)
+	}
+
+	return m.withLock(func(conn *sqlx.Conn) error {
+		applied, err := appliedVersions(conn)
 		if err != nil {
-			return fmt.Errorf("cannot read %s: %w", scriptPath, err)
+			return fmt.Errorf("Migrator.Down: %w", err)
 		}
 
-		statements := strings.Split(string(content), ";")
-		for _, stmt := range statements {
-			stmt = strings.TrimSpace(stmt)
-			if stmt == "" {
+		for i := len(migrations) - 1; i >= 0 && steps > 0; i-- {
+			migration := migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
 				continue
 			}
 
-			_, err := db.Exec(stmt)
-			if err != nil {
-				if strings.Contains(err.Error(), "already exists") || strings.Contains(err.Error(), "duplicate key") {
-					log.Printf("Skipping error in %s: %v", scriptPath, err)
-					continue
+			if migration.Down == "" {
+				return fmt.Errorf("Migrator.Down: migration %d_%s has no down script", migration.Version, migration.Name)
+			}
+
+			if err := applyMigration(conn, migration, false); err != nil {
+				return fmt.Errorf("Migrator.Down: %w", err)
+			}
+
+			steps--
+		}
+
+		return nil
+	})
+}
+
+// Откатить и заново применить последнюю миграцию. Оба шага выполняются
+// под одной блокировкой в одной транзакции: если повторное применение
+// не удалось, откат тоже отменяется
+func (m *Migrator) Redo() error {
+	migrations, err := m.Load()
+	if err != nil {
+		return fmt.Errorf("Migrator.Redo: %w", err)
+	}
+
+	return m.withLock(func(conn *sqlx.Conn) error {
+		applied, err := appliedVersions(conn)
+		if err != nil {
+			return fmt.Errorf("Migrator.Redo: %w", err)
+		}
+
+		for i := len(migrations) - 1; i >= 0; i-- {
+			migration := migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+
+			if migration.Down == "" {
+				return fmt.Errorf("Migrator.Redo: migration %d_%s has no down script", migration.Version, migration.Name)
+			}
+
+			if err := inTransaction(conn, func(tx *sqlx.Tx) error {
+				if err := runMigration(tx, migration, false); err != nil {
+					return err
 				}
-				return fmt.Errorf("error executing statement in %s: %w", scriptPath, err)
+				return runMigration(tx, migration, true)
+			}); err != nil {
+				return fmt.Errorf("Migrator.Redo: %w", err)
 			}
+
+			return nil
 		}
 
-		log.Printf("Migration %s applied", scriptPath)
+		return nil
+	})
+}
+
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	migrations, err := m.Load()
+	if err != nil {
+		return nil, fmt.Errorf("Migrator.Status: %w", err)
 	}
 
+	var statuses []MigrationStatus
+
+	err = m.withLock(func(conn *sqlx.Conn) error {
+		applied, err := appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range migrations {
+			status := MigrationStatus{Migration: migration}
+			if appliedAt, ok := applied[migration.Version]; ok {
+				status.AppliedAt = &appliedAt
+			}
+
+			statuses = append(statuses, status)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Migrator.Status: %w", err)
+	}
+
+	return statuses, nil
+}
+
+// Run выполняет подкоманду migrate: up, down [N], status, redo
+func (m *Migrator) Run(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: migrate up|down [N]|status|redo")
+	}
+
+	switch args[0] {
+	case "up":
+		return m.Up()
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("migrate down: invalid number of steps %q", args[1])
+			}
+			steps = n
+		}
+		return m.Down(steps)
+
+	case "redo":
+		return m.Redo()
+
+	case "status":
+		statuses, err := m.Status()
+		if err != nil {
+			return err
+		}
+
+		for _, status := range statuses {
+			applied := "pending"
+			if status.AppliedAt != nil {
+				applied = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-30s %s\n", status.Version, status.Name, applied)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("migrate: unknown command %q", args[0])
+	}
+}
+
+func (m *Migrator) withLock(fn func(conn *sqlx.Conn) error) error {
+	ctx := context.Background()
+
+	conn, err := m.db.Connx(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot get connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationsLockKey); err != nil {
+		return fmt.Errorf("cannot acquire migrations lock: %w", err)
+	}
+	defer func() {
+		if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, migrationsLockKey); err != nil {
+			log.Printf("cannot release migrations lock: %v", err)
+		}
+	}()
+
+	_, err = conn.ExecContext(ctx, `
+	    CREATE TABLE IF NOT EXISTS schema_migrations (
+		    version BIGINT PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			applied_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("cannot create schema_migrations: %w", err)
+	}
+
+	return fn(conn)
+}
+
+func appliedVersions(conn *sqlx.Conn) (map[int64]time.Time, error) {
+	var rows []struct {
+		Version   int64     `db:"version"`
+		AppliedAt time.Time `db:"applied_at"`
+	}
+
+	err := conn.SelectContext(context.Background(), &rows, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read schema_migrations: %w", err)
+	}
+
+	applied := make(map[int64]time.Time, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row.AppliedAt
+	}
+
+	return applied, nil
+}
+
+func applyMigration(conn *sqlx.Conn, migration Migration, up bool) error {
+	return inTransaction(conn, func(tx *sqlx.Tx) error {
+		return runMigration(tx, migration, up)
+	})
+}
+
+func inTransaction(conn *sqlx.Conn, fn func(tx *sqlx.Tx) error) (err error) {
+	ctx := context.Background()
+
+	tx, err := conn.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("cannot begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = fn(tx); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("cannot commit: %w", err)
+	}
+
+	return nil
+}
+
+func runMigration(tx *sqlx.Tx, migration Migration, up bool) error {
+	ctx := context.Background()
+	direction, script := "up", migration.Up
+	if !up {
+		direction, script = "down", migration.Down
+	}
+
+	log.Printf("Running migration %d_%s (%s)", migration.Version, migration.Name, direction)
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return fmt.Errorf("migration %d_%s %s: %w", migration.Version, migration.Name, direction, err)
+	}
+
+	var err error
+	if up {
+		_, err = tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, migration.Version, migration.Name)
+	} else {
+		_, err = tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = $1`, migration.Version)
+	}
+	if err != nil {
+		return fmt.Errorf("migration %d: cannot record version: %w", migration.Version, err)
+	}
+
+	log.Printf("Migration %d_%s done (%s)", migration.Version, migration.Name, direction)
+
 	return nil
 }
@@ -0,0 +1,171 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/gratefultolord/ac_signup_bot/internal/lifecycle"
+)
+
+const (
+	OutboxPending = "pending"
+	OutboxSent    = "sent"
+	OutboxDead    = "dead"
+
+	// Уведомления по обращениям в поддержку
+	NotificationSupportReply  = "support_reply"
+	NotificationSupportClosed = "support_closed"
+)
+
+// Notification — сообщение пользователю из outbox. Kind совпадает
+// с новым статусом заявки (approved, rejected, needs_revision)
+// либо относится к обращению: support_reply, support_closed
+type Notification struct {
+	ID               int64      `db:"id"`
+	Kind             string     `db:"kind"`
+	TelegramUserID   int64      `db:"telegram_user_id"`
+	RequestID        *int64     `db:"request_id"`
+	ConversationID   *int64     `db:"conversation_id"`
+	SupportMessageID *int64     `db:"support_message_id"`
+	Reason           *string    `db:"reason"`
+	Status           string     `db:"status"`
+	Attempts         int        `db:"attempts"`
+	NextAttemptAt    time.Time  `db:"next_attempt_at"`
+	LastError        *string    `db:"last_error"`
+	CreatedAt        time.Time  `db:"created_at"`
+	SentAt           *time.Time `db:"sent_at"`
+}
+
+type OutboxRepository struct {
+	db *sqlx.DB
+}
+
+func NewOutboxRepository(db *sqlx.DB) *OutboxRepository {
+	return &OutboxRepository{
+		db: db,
+	}
+}
+
+// Добавить уведомление в рамках транзакции, меняющей статус заявки
+func enqueueNotification(tx *sqlx.Tx, kind lifecycle.Status, telegramUserID, requestID int64, reason *string) error {
+	_, err := tx.Exec(`
+	    INSERT INTO notification_outbox (kind, telegram_user_id, request_id, reason)
+		VALUES ($1, $2, $3, $4)
+	`, kind, telegramUserID, requestID, reason)
+
+	return err
+}
+
+// Добавить уведомление по обращению в рамках транзакции, сохраняющей ответ
+// или закрывающей обращение. messageID задаётся только для ответа
+func enqueueSupportNotification(tx *sqlx.Tx, kind string, conversation *SupportConversation, messageID *int64) error {
+	_, err := tx.Exec(`
+	    INSERT INTO notification_outbox (kind, telegram_user_id, conversation_id, support_message_id)
+		VALUES ($1, $2, $3, $4)
+	`, kind, conversation.TelegramUserID, conversation.ID, messageID)
+
+	return err
+}
+
+// Уведомления, время очередной попытки которых наступило
+func (r *OutboxRepository) GetDue(limit int) ([]Notification, error) {
+	var notifications []Notification
+
+	err := r.db.Select(&notifications, `
+	    SELECT * FROM notification_outbox
+		WHERE status = 'pending' AND next_attempt_at <= CURRENT_TIMESTAMP
+		ORDER BY next_attempt_at, id
+		LIMIT $1
+	`, limit)
+
+	if err != nil {
+		return nil, fmt.Errorf("OutboxRepository.GetDue: %w", err)
+	}
+
+	return notifications, nil
+}
+
+func (r *OutboxRepository) MarkSent(notificationID int64) error {
+	_, err := r.db.Exec(`
+	    UPDATE notification_outbox
+		SET status = 'sent', attempts = attempts + 1, sent_at = CURRENT_TIMESTAMP, last_error = NULL
+		WHERE id = $1
+	`, notificationID)
+
+	if err != nil {
+		return fmt.Errorf("OutboxRepository.MarkSent: %w", err)
+	}
+
+	return nil
+}
+
+// Записать неудачную попытку: назначить следующую или, если nextAttemptAt nil, отправить в dead
+func (r *OutboxRepository) MarkFailed(notificationID int64, lastError string, nextAttemptAt *time.Time) error {
+	status := OutboxPending
+	if nextAttemptAt == nil {
+		status = OutboxDead
+	}
+
+	_, err := r.db.Exec(`
+	    UPDATE notification_outbox
+		SET status = $1, attempts = attempts + 1, last_error = $2,
+		    next_attempt_at = COALESCE($3, next_attempt_at)
+		WHERE id = $4
+	`, status, lastError, nextAttemptAt, notificationID)
+
+	if err != nil {
+		return fmt.Errorf("OutboxRepository.MarkFailed: %w", err)
+	}
+
+	return nil
+}
+
+// Недоставленные: в dead и ожидающие повтора после ошибки
+func (r *OutboxRepository) ListUndelivered(page Page) ([]Notification, error) {
+	var notifications []Notification
+
+	err := r.db.Select(&notifications, `
+	    SELECT * FROM notification_outbox
+		WHERE status = 'dead' OR (status = 'pending' AND attempts > 0)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $1 OFFSET $2
+	`, page.Limit, page.Offset)
+
+	if err != nil {
+		return nil, fmt.Errorf("OutboxRepository.ListUndelivered: %w", err)
+	}
+
+	return notifications, nil
+}
+
+func (r *OutboxRepository) CountUndelivered() (int, error) {
+	var count int
+
+	err := r.db.Get(&count, `
+	    SELECT COUNT(*) FROM notification_outbox
+		WHERE status = 'dead' OR (status = 'pending' AND attempts > 0)
+	`)
+
+	if err != nil {
+		return 0, fmt.Errorf("OutboxRepository.CountUndelivered: %w", err)
+	}
+
+	return count, nil
+}
+
+// Вернуть уведомление в очередь с немедленной попыткой
+func (r *OutboxRepository) Retry(notificationID int64) error {
+	_, err := r.db.Exec(`
+	    UPDATE notification_outbox
+		SET status = 'pending', attempts = 0, next_attempt_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status <> 'sent'
+	`, notificationID)
+
+	if err != nil {
+		return fmt.Errorf("OutboxRepository.Retry: %w", err)
+	}
+
+	return nil
+}
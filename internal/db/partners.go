@@ -0,0 +1,252 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	DiscountPercent = "percent"
+	DiscountFixed   = "fixed"
+)
+
+type Partner struct {
+	ID                  int64     `db:"id"`
+	CategoryID          int64     `db:"category_id"`
+	Title               string    `db:"title"`
+	Description         *string   `db:"description"`
+	Address             *string   `db:"address"`
+	URL                 *string   `db:"url"`
+	PhotoPath           *string   `db:"photo_path"`
+	DiscountType        *string   `db:"discount_type"`
+	DiscountPercentSize *float64  `db:"discount_percent_size"`
+	DiscountFixedSize   *int64    `db:"discount_fixed_size"` // в рублях
+	SortOrder           int       `db:"sort_order"`
+	IsPublished         bool      `db:"is_published"`
+	CreatedAt           time.Time `db:"created_at"`
+	UpdatedAt           time.Time `db:"updated_at"`
+}
+
+// Validate проверяет обязательные поля и соответствие размера скидки её типу
+func (p *Partner) Validate() error {
+	if p.CategoryID == 0 {
+		return fmt.Errorf("%w: partner category is required", ErrValidation)
+	}
+
+	if strings.TrimSpace(p.Title) == "" {
+		return fmt.Errorf("%w: partner title is required", ErrValidation)
+	}
+
+	if p.DiscountType == nil {
+		if p.DiscountPercentSize != nil || p.DiscountFixedSize != nil {
+			return fmt.Errorf("%w: discount size is set without discount type", ErrValidation)
+		}
+		return nil
+	}
+
+	switch *p.DiscountType {
+	case DiscountPercent:
+		if p.DiscountPercentSize == nil || *p.DiscountPercentSize <= 0 || *p.DiscountPercentSize > 100 {
+			return fmt.Errorf("%w: percent discount must be in (0, 100]", ErrValidation)
+		}
+		if p.DiscountFixedSize != nil {
+			return fmt.Errorf("%w: percent discount cannot have fixed size", ErrValidation)
+		}
+
+	case DiscountFixed:
+		if p.DiscountFixedSize == nil || *p.DiscountFixedSize <= 0 {
+			return fmt.Errorf("%w: fixed discount must be positive", ErrValidation)
+		}
+		if p.DiscountPercentSize != nil {
+			return fmt.Errorf("%w: fixed discount cannot have percent size", ErrValidation)
+		}
+
+	default:
+		return fmt.Errorf("%w: unknown discount type %q", ErrValidation, *p.DiscountType)
+	}
+
+	return nil
+}
+
+type PartnerRepository struct {
+	db *sqlx.DB
+}
+
+func NewPartnerRepository(db *sqlx.DB) *PartnerRepository {
+	return &PartnerRepository{
+		db: db,
+	}
+}
+
+func (r *PartnerRepository) Create(partner *Partner) error {
+	if err := partner.Validate(); err != nil {
+		return fmt.Errorf("PartnerRepository.Create: %w", err)
+	}
+
+	err := r.db.Get(partner, `
+	    INSERT INTO partners
+		(category_id, title, description, address, url, photo_path,
+		discount_type, discount_percent_size, discount_fixed_size, sort_order, is_published)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING *
+	`,
+		partner.CategoryID,
+		partner.Title,
+		partner.Description,
+		partner.Address,
+		partner.URL,
+		partner.PhotoPath,
+		partner.DiscountType,
+		partner.DiscountPercentSize,
+		partner.DiscountFixedSize,
+		partner.SortOrder,
+		partner.IsPublished,
+	)
+	if err != nil {
+		return fmt.Errorf("PartnerRepository.Create: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PartnerRepository) GetByID(partnerID int64) (*Partner, error) {
+	var partner Partner
+
+	err := r.db.Get(&partner, `
+	    SELECT * FROM partners
+		WHERE id = $1
+	`, partnerID)
+
+	if err != nil {
+		return nil, fmt.Errorf("PartnerRepository.GetByID: %w", err)
+	}
+
+	return &partner, nil
+}
+
+func (r *PartnerRepository) ListByCategory(categoryID int64, page Page) ([]Partner, error) {
+	var partners []Partner
+
+	err := r.db.Select(&partners, `
+	    SELECT * FROM partners
+		WHERE category_id = $1
+		ORDER BY sort_order, title, id
+		LIMIT $2 OFFSET $3
+	`, categoryID, page.Limit, page.Offset)
+
+	if err != nil {
+		return nil, fmt.Errorf("PartnerRepository.ListByCategory: %w", err)
+	}
+
+	return partners, nil
+}
+
+func (r *PartnerRepository) CountByCategory(categoryID int64) (int, error) {
+	var count int
+
+	err := r.db.Get(&count, `
+	    SELECT COUNT(*) FROM partners
+		WHERE category_id = $1
+	`, categoryID)
+
+	if err != nil {
+		return 0, fmt.Errorf("PartnerRepository.CountByCategory: %w", err)
+	}
+
+	return count, nil
+}
+
+// Опубликованные партнёры категории, которые видят участники
+func (r *PartnerRepository) ListPublishedByCategory(categoryID int64, page Page) ([]Partner, error) {
+	var partners []Partner
+
+	err := r.db.Select(&partners, `
+	    SELECT * FROM partners
+		WHERE category_id = $1 AND is_published
+		ORDER BY sort_order, title, id
+		LIMIT $2 OFFSET $3
+	`, categoryID, page.Limit, page.Offset)
+
+	if err != nil {
+		return nil, fmt.Errorf("PartnerRepository.ListPublishedByCategory: %w", err)
+	}
+
+	return partners, nil
+}
+
+func (r *PartnerRepository) CountPublishedByCategory(categoryID int64) (int, error) {
+	var count int
+
+	err := r.db.Get(&count, `
+	    SELECT COUNT(*) FROM partners
+		WHERE category_id = $1 AND is_published
+	`, categoryID)
+
+	if err != nil {
+		return 0, fmt.Errorf("PartnerRepository.CountPublishedByCategory: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *PartnerRepository) SetPublished(partnerID int64, published bool) error {
+	_, err := r.db.Exec(`
+	    UPDATE partners
+		SET is_published = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+	`, published, partnerID)
+
+	if err != nil {
+		return fmt.Errorf("PartnerRepository.SetPublished: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PartnerRepository) Update(partner *Partner) error {
+	if err := partner.Validate(); err != nil {
+		return fmt.Errorf("PartnerRepository.Update: %w", err)
+	}
+
+	err := r.db.Get(partner, `
+	    UPDATE partners
+		SET category_id = $1, title = $2, description = $3, address = $4, url = $5,
+		    photo_path = $6, discount_type = $7, discount_percent_size = $8,
+			discount_fixed_size = $9, sort_order = $10, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $11
+		RETURNING *
+	`,
+		partner.CategoryID,
+		partner.Title,
+		partner.Description,
+		partner.Address,
+		partner.URL,
+		partner.PhotoPath,
+		partner.DiscountType,
+		partner.DiscountPercentSize,
+		partner.DiscountFixedSize,
+		partner.SortOrder,
+		partner.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("PartnerRepository.Update: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PartnerRepository) Delete(partnerID int64) error {
+	_, err := r.db.Exec(`
+	    DELETE FROM partners
+		WHERE id = $1
+	`, partnerID)
+
+	if err != nil {
+		return fmt.Errorf("PartnerRepository.Delete: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,242 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/gratefultolord/ac_signup_bot/internal/lifecycle"
+)
+
+// ErrDuplicatePayment возвращается, если платёж с таким charge ID уже записан
+var ErrDuplicatePayment = errors.New("payment already recorded")
+
+// ErrPaymentNotApplied возвращается, если заявку нельзя перевести в paid.
+// Платёж уже записан отдельно, участник не создан
+var ErrPaymentNotApplied = errors.New("payment recorded but not applied to request")
+
+type Payment struct {
+	ID               int64     `db:"id"`
+	UserID           *int64    `db:"user_id"`
+	TelegramUserID   int64     `db:"telegram_user_id"`
+	TelegramChargeID string    `db:"telegram_charge_id"`
+	ProviderChargeID string    `db:"provider_charge_id"`
+	Amount           int64     `db:"amount"`
+	Currency         string    `db:"currency"`
+	Payload          string    `db:"payload"`
+	Tariff           string    `db:"tariff"`
+	CreatedAt        time.Time `db:"created_at"`
+}
+
+type PaymentRepository struct {
+	db *sqlx.DB
+}
+
+func NewPaymentRepository(db *sqlx.DB) *PaymentRepository {
+	return &PaymentRepository{
+		db: db,
+	}
+}
+
+// Записать платёж в журнал отдельной транзакцией, до того как его применять:
+// деньги уже списаны, и запись не должна пропасть из-за ошибки дальше.
+// При повторной доставке апдейта в payment загружается уже записанный
+// платёж и возвращается ErrDuplicatePayment. Пустой UserID у него значит,
+// что платёж ещё не применён
+func (r *PaymentRepository) Record(payment *Payment) error {
+	err := r.db.Get(payment, `
+	    INSERT INTO payments
+		(telegram_user_id, telegram_charge_id, provider_charge_id, amount, currency, payload, tariff)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (telegram_charge_id) DO NOTHING
+		RETURNING *
+	`,
+		payment.TelegramUserID,
+		payment.TelegramChargeID,
+		payment.ProviderChargeID,
+		payment.Amount,
+		payment.Currency,
+		payment.Payload,
+		payment.Tariff,
+	)
+	if err == nil {
+		return nil
+	}
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("PaymentRepository.Record: %w", err)
+	}
+
+	err = r.db.Get(payment, `
+	    SELECT * FROM payments
+		WHERE telegram_charge_id = $1
+	`, payment.TelegramChargeID)
+	if err != nil {
+		return fmt.Errorf("PaymentRepository.Record: %w", err)
+	}
+
+	return fmt.Errorf("PaymentRepository.Record: %w", ErrDuplicatePayment)
+}
+
+// Применить записанный платёж за регистрацию: создание (или продление) участника,
+// выдача кода входа и перевод заявки в paid выполняются в одной транзакции.
+// Если заявка уже не ждёт оплаты, возвращается ErrPaymentNotApplied
+func (r *PaymentRepository) ApplySignup(paymentID int64, user *UserShort, months int, requestID int64, authCode string) (*User, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("PaymentRepository.ApplySignup: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := lockUnappliedPayment(tx, paymentID); err != nil {
+		return nil, fmt.Errorf("PaymentRepository.ApplySignup: %w", err)
+	}
+
+	request, err := lockRequest(tx, requestID)
+	if err != nil {
+		return nil, fmt.Errorf("PaymentRepository.ApplySignup: link request: %w", err)
+	}
+
+	if err := lifecycle.Transition(request.Status, lifecycle.Paid); err != nil {
+		return nil, fmt.Errorf("PaymentRepository.ApplySignup: %w: %w", ErrPaymentNotApplied, err)
+	}
+
+	var created User
+
+	err = tx.Get(&created, `
+	    INSERT INTO users
+		(telegram_user_id, first_name, last_name, birth_date, status, phone_number, photo_path, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NULL, CURRENT_TIMESTAMP + make_interval(months => $7))
+		ON CONFLICT (telegram_user_id) DO UPDATE
+		SET expires_at = GREATEST(users.expires_at, CURRENT_TIMESTAMP) + make_interval(months => $7),
+		    membership_status = 'active', updated_at = CURRENT_TIMESTAMP
+		RETURNING *
+	`,
+		user.TelegramUserID,
+		user.FirstName,
+		user.LastName,
+		user.BirthDate,
+		user.Status,
+		user.PhoneNumber,
+		months,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("PaymentRepository.ApplySignup: upsert user: %w", err)
+	}
+
+	if _, err := tx.Exec(`UPDATE payments SET user_id = $1 WHERE id = $2`, created.ID, paymentID); err != nil {
+		return nil, fmt.Errorf("PaymentRepository.ApplySignup: link payment: %w", err)
+	}
+
+	_, err = tx.Exec(`
+	    INSERT INTO tokens
+		(user_id, code, phone_number)
+		VALUES ($1, $2, $3)
+	`, created.ID, authCode, created.PhoneNumber)
+	if err != nil {
+		return nil, fmt.Errorf("PaymentRepository.ApplySignup: create token: %w", err)
+	}
+
+	_, err = tx.Exec(`
+	    UPDATE registration_requests
+		SET user_id = $1, status = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+	`, created.ID, lifecycle.Paid, requestID)
+	if err != nil {
+		return nil, fmt.Errorf("PaymentRepository.ApplySignup: link request: %w", err)
+	}
+
+	err = recordRequestEvent(tx, RequestEvent{
+		RequestID:   requestID,
+		ActorType:   ActorUser,
+		ActorChatID: user.TelegramUserID,
+		OldStatus:   &request.Status,
+		NewStatus:   lifecycle.Paid,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("PaymentRepository.ApplySignup: cannot record event: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("PaymentRepository.ApplySignup: %w", err)
+	}
+
+	return &created, nil
+}
+
+// Применить записанный платёж за продление: подписка продлевается от текущей
+// даты окончания, а если она уже прошла — от момента оплаты
+func (r *PaymentRepository) ApplyRenewal(paymentID, telegramUserID int64, months int) (*User, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("PaymentRepository.ApplyRenewal: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := lockUnappliedPayment(tx, paymentID); err != nil {
+		return nil, fmt.Errorf("PaymentRepository.ApplyRenewal: %w", err)
+	}
+
+	var user User
+
+	err = tx.Get(&user, `
+	    UPDATE users
+		SET expires_at = GREATEST(expires_at, CURRENT_TIMESTAMP) + make_interval(months => $1),
+		    membership_status = 'active', updated_at = CURRENT_TIMESTAMP
+		WHERE telegram_user_id = $2
+		RETURNING *
+	`, months, telegramUserID)
+	if err != nil {
+		return nil, fmt.Errorf("PaymentRepository.ApplyRenewal: extend subscription: %w", err)
+	}
+
+	if _, err := tx.Exec(`UPDATE payments SET user_id = $1 WHERE id = $2`, user.ID, paymentID); err != nil {
+		return nil, fmt.Errorf("PaymentRepository.ApplyRenewal: link payment: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("PaymentRepository.ApplyRenewal: %w", err)
+	}
+
+	return &user, nil
+}
+
+func (r *PaymentRepository) GetByTelegramUserID(telegramUserID int64) ([]Payment, error) {
+	var payments []Payment
+
+	err := r.db.Select(&payments, `
+	    SELECT * FROM payments
+		WHERE telegram_user_id = $1
+		ORDER BY created_at DESC
+	`, telegramUserID)
+
+	if err != nil {
+		return nil, fmt.Errorf("PaymentRepository.GetByTelegramUserID: %w", err)
+	}
+
+	return payments, nil
+}
+
+// Заблокировать платёж до конца транзакции. Уже применённый платёж
+// (с участником) даёт ErrDuplicatePayment, чтобы не применить его дважды
+func lockUnappliedPayment(tx *sqlx.Tx, paymentID int64) error {
+	var userID *int64
+
+	err := tx.Get(&userID, `
+	    SELECT user_id FROM payments
+		WHERE id = $1
+		FOR UPDATE
+	`, paymentID)
+	if err != nil {
+		return fmt.Errorf("lock payment: %w", err)
+	}
+
+	if userID != nil {
+		return ErrDuplicatePayment
+	}
+
+	return nil
+}
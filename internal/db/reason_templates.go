@@ -0,0 +1,147 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/gratefultolord/ac_signup_bot/internal/lifecycle"
+)
+
+// Причина уходит пользователю в уведомлении, поэтому шаблон должен быть коротким
+const ReasonTemplateMaxLength = 300
+
+// ReasonTemplate — готовая причина отклонения или отправки на доработку
+type ReasonTemplate struct {
+	ID        int64            `db:"id"`
+	Kind      lifecycle.Status `db:"kind"`
+	Text      string           `db:"text"`
+	CreatedAt time.Time        `db:"created_at"`
+	UpdatedAt time.Time        `db:"updated_at"`
+}
+
+func (t *ReasonTemplate) Validate() error {
+	if t.Kind != lifecycle.Rejected && t.Kind != lifecycle.NeedsRevision {
+		return fmt.Errorf("%w: unsupported template kind %q", ErrValidation, t.Kind)
+	}
+
+	if strings.TrimSpace(t.Text) == "" {
+		return fmt.Errorf("%w: template text is required", ErrValidation)
+	}
+
+	if utf8.RuneCountInString(t.Text) > ReasonTemplateMaxLength {
+		return fmt.Errorf("%w: template text is longer than %d characters", ErrValidation, ReasonTemplateMaxLength)
+	}
+
+	return nil
+}
+
+// ReasonTemplateUsage — шаблон со статистикой решений, принятых с ним
+type ReasonTemplateUsage struct {
+	ReasonTemplate
+	Uses       int        `db:"uses"`
+	RecentUses int        `db:"recent_uses"`
+	LastUsedAt *time.Time `db:"last_used_at"`
+}
+
+type ReasonTemplateRepository struct {
+	db *sqlx.DB
+}
+
+func NewReasonTemplateRepository(db *sqlx.DB) *ReasonTemplateRepository {
+	return &ReasonTemplateRepository{
+		db: db,
+	}
+}
+
+func (r *ReasonTemplateRepository) Create(template *ReasonTemplate) error {
+	if err := template.Validate(); err != nil {
+		return fmt.Errorf("ReasonTemplateRepository.Create: %w", err)
+	}
+
+	err := r.db.Get(template, `
+	    INSERT INTO reason_templates (kind, text)
+		VALUES ($1, $2)
+		RETURNING *
+	`, template.Kind, template.Text)
+
+	if err != nil {
+		return fmt.Errorf("ReasonTemplateRepository.Create: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ReasonTemplateRepository) GetByID(templateID int64) (*ReasonTemplate, error) {
+	var template ReasonTemplate
+
+	err := r.db.Get(&template, `
+	    SELECT * FROM reason_templates
+		WHERE id = $1
+	`, templateID)
+
+	if err != nil {
+		return nil, fmt.Errorf("ReasonTemplateRepository.GetByID: %w", err)
+	}
+
+	return &template, nil
+}
+
+// Шаблоны одного вида, сначала самые используемые. RecentUses — за последние 30 дней
+func (r *ReasonTemplateRepository) List(kind lifecycle.Status) ([]ReasonTemplateUsage, error) {
+	var templates []ReasonTemplateUsage
+
+	err := r.db.Select(&templates, `
+	    SELECT t.*,
+		       COUNT(e.id) AS uses,
+		       COUNT(e.id) FILTER (WHERE e.created_at > CURRENT_TIMESTAMP - INTERVAL '30 days') AS recent_uses,
+		       MAX(e.created_at) AS last_used_at
+		FROM reason_templates t
+		LEFT JOIN registration_request_events e ON e.template_id = t.id
+		WHERE t.kind = $1
+		GROUP BY t.id
+		ORDER BY uses DESC, t.id
+	`, kind)
+
+	if err != nil {
+		return nil, fmt.Errorf("ReasonTemplateRepository.List: %w", err)
+	}
+
+	return templates, nil
+}
+
+func (r *ReasonTemplateRepository) Update(template *ReasonTemplate) error {
+	if err := template.Validate(); err != nil {
+		return fmt.Errorf("ReasonTemplateRepository.Update: %w", err)
+	}
+
+	err := r.db.Get(template, `
+	    UPDATE reason_templates
+		SET text = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+		RETURNING *
+	`, template.Text, template.ID)
+
+	if err != nil {
+		return fmt.Errorf("ReasonTemplateRepository.Update: %w", err)
+	}
+
+	return nil
+}
+
+// Удалить шаблон; в истории заявок остаётся текст причины, ссылка на шаблон обнуляется
+func (r *ReasonTemplateRepository) Delete(templateID int64) error {
+	_, err := r.db.Exec(`
+	    DELETE FROM reason_templates
+		WHERE id = $1
+	`, templateID)
+
+	if err != nil {
+		return fmt.Errorf("ReasonTemplateRepository.Delete: %w", err)
+	}
+
+	return nil
+}
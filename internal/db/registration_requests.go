@@ -4,28 +4,41 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/AlekSi/pointer"
 	"github.com/jmoiron/sqlx"
+
+	"github.com/gratefultolord/ac_signup_bot/internal/lifecycle"
 )
 
 type RegistrationRequest struct {
-	ID              int64     `db:"id"`
-	UserID          *int64    `db:"user_id"`
-	TelegramUserID  int64     `db:"telegram_user_id"`
-	FirstName       string    `db:"first_name"`
-	LastName        string    `db:"last_name"`
-	BirthDate       time.Time `db:"birth_date"`
-	UserStatus      string    `db:"user_status"`
-	DocumentPath    string    `db:"document_path"`
-	PhoneNumber     string    `db:"phone_number"`
-	Status          string    `db:"status"`
-	RejectionReason *string   `db:"rejection_reason"`
-	CreatedAt       time.Time `db:"created_at"`
-	UpdatedAt       time.Time `db:"updated_at"`
+	ID              int64            `db:"id"`
+	UserID          *int64           `db:"user_id"`
+	TelegramUserID  int64            `db:"telegram_user_id"`
+	FirstName       string           `db:"first_name"`
+	LastName        string           `db:"last_name"`
+	BirthDate       time.Time        `db:"birth_date"`
+	UserStatus      string           `db:"user_status"`
+	DocumentPath    string           `db:"document_path"`
+	PhoneNumber     string           `db:"phone_number"`
+	Status          lifecycle.Status `db:"status"`
+	RejectionReason *string          `db:"rejection_reason"`
+	RevisionCount   int              `db:"revision_count"`
+	ClaimedBy       *int64           `db:"claimed_by"`
+	ClaimedAt       *time.Time       `db:"claimed_at"`
+	HeldBy          *int64           `db:"held_by"`
+	HoldNote        *string          `db:"hold_note"`
+	RemindAt        *time.Time       `db:"remind_at"`
+	CreatedAt       time.Time        `db:"created_at"`
+	UpdatedAt       time.Time        `db:"updated_at"`
 }
 
+// ErrRequestNotClaimed — заявка закреплена за другим админом или ни за кем.
+// Уже рассмотренная заявка вместо этого даёт *lifecycle.TransitionError
+var ErrRequestNotClaimed = errors.New("request is not claimed by this admin")
+
 type RegistrationRequestShort struct {
 	ID          int64     `db:"id"`
 	FirstName   string    `db:"first_name"`
@@ -48,11 +61,18 @@ func NewRegistrationRequestRepository(db *sqlx.DB) *RegistrationRequestRepositor
 }
 
 func (r *RegistrationRequestRepository) Create(req *RegistrationRequest) error {
-	_, err := r.db.Exec(`
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("RegistrationRequestRepository.Create: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.Get(&req.ID, `
 	    INSERT INTO registration_requests
 		(telegram_user_id, first_name, last_name, birth_date, user_status,
 		document_path, phone_number, status)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, 'pending')
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
 	`,
 		req.TelegramUserID,
 		req.FirstName,
@@ -61,11 +81,26 @@ func (r *RegistrationRequestRepository) Create(req *RegistrationRequest) error {
 		req.UserStatus,
 		req.DocumentPath,
 		req.PhoneNumber,
+		lifecycle.Pending,
 	)
 	if err != nil {
 		return fmt.Errorf("RegistrationRequestRepository.Create: %w", err)
 	}
 
+	err = recordRequestEvent(tx, RequestEvent{
+		RequestID:   req.ID,
+		ActorType:   ActorUser,
+		ActorChatID: req.TelegramUserID,
+		NewStatus:   lifecycle.Pending,
+	})
+	if err != nil {
+		return fmt.Errorf("RegistrationRequestRepository.Create: cannot record event: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("RegistrationRequestRepository.Create: %w", err)
+	}
+
 	return nil
 }
 
@@ -86,35 +121,311 @@ func (r *RegistrationRequestRepository) GetLatestByTelegramUserID(telegramUserID
 	return &req, nil
 }
 
-func (r *RegistrationRequestRepository) UpdateDocumentAndStatus(requestID int64, telegramUserID int64, newPath string, newStatus string) error {
-	_, err := r.db.Exec(`
+// Повторно отправить заявку после доработки: новые данные, статус pending
+func (r *RegistrationRequestRepository) Resubmit(req *RegistrationRequest) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("RegistrationRequestRepository.Resubmit: %w", err)
+	}
+	defer tx.Rollback()
+
+	current, err := lockRequest(tx, req.ID)
+	if err != nil {
+		return fmt.Errorf("RegistrationRequestRepository.Resubmit: %w", err)
+	}
+
+	if current.TelegramUserID != req.TelegramUserID {
+		return fmt.Errorf("RegistrationRequestRepository.Resubmit: request %d belongs to another user", req.ID)
+	}
+
+	if err := lifecycle.Transition(current.Status, lifecycle.Pending); err != nil {
+		return fmt.Errorf("RegistrationRequestRepository.Resubmit: %w", err)
+	}
+
+	_, err = tx.Exec(`
 	    UPDATE registration_requests
-		SET document_path = $1, status = $2, rejection_reason = NULL, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $3 AND telegram_user_id = $4
-	`, newPath, newStatus, requestID, telegramUserID)
+		SET first_name = $1, last_name = $2, birth_date = $3, user_status = $4,
+		    phone_number = $5, document_path = $6, status = $7, rejection_reason = NULL,
+			revision_count = revision_count + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $8
+	`,
+		req.FirstName,
+		req.LastName,
+		req.BirthDate,
+		req.UserStatus,
+		req.PhoneNumber,
+		req.DocumentPath,
+		lifecycle.Pending,
+		req.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("RegistrationRequestRepository.Resubmit: %w", err)
+	}
 
+	err = recordRequestEvent(tx, RequestEvent{
+		RequestID:   req.ID,
+		ActorType:   ActorUser,
+		ActorChatID: req.TelegramUserID,
+		OldStatus:   &current.Status,
+		NewStatus:   lifecycle.Pending,
+	})
 	if err != nil {
-		return fmt.Errorf("RegistrationRequestRepository.UpdateDocumentAndStatus: %w", err)
+		return fmt.Errorf("RegistrationRequestRepository.Resubmit: cannot record event: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("RegistrationRequestRepository.Resubmit: %w", err)
 	}
 
 	return nil
 }
 
-// Обновить статус заявки
-func (r *RegistrationRequestRepository) UpdateStatus(requestID int64, newStatus string, rejectionReason *string) error {
-	_, err := r.db.Exec(`
-	    UPDATE registration_requests
-		SET status = $1, rejection_reason = $2, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $3
-	`, newStatus, rejectionReason, requestID)
+// Решение админа по заявке. Переход проверяется по lifecycle, а принимается
+// решение, только если заявка закреплена за этим админом; закрепление
+// снимается, а запись в истории и уведомление пользователю появляются
+// в той же транзакции. templateID — шаблон, из которого взята причина
+func (r *RegistrationRequestRepository) UpdateStatus(requestID, adminID int64, newStatus lifecycle.Status, rejectionReason *string, templateID *int64) error {
+	if !newStatus.IsDecision() {
+		return fmt.Errorf("RegistrationRequestRepository.UpdateStatus: %q is not an admin decision", newStatus)
+	}
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("RegistrationRequestRepository.UpdateStatus: %w", err)
+	}
+	defer tx.Rollback()
+
+	current, err := lockRequest(tx, requestID)
+	if err != nil {
+		return fmt.Errorf("RegistrationRequestRepository.UpdateStatus: %w", err)
+	}
+
+	if err := lifecycle.Transition(current.Status, newStatus); err != nil {
+		return fmt.Errorf("RegistrationRequestRepository.UpdateStatus: %w", err)
+	}
+
+	if current.ClaimedBy == nil || *current.ClaimedBy != adminID {
+		return fmt.Errorf("RegistrationRequestRepository.UpdateStatus: %w", ErrRequestNotClaimed)
+	}
+
+	var adminChatID int64
+
+	err = tx.Get(&adminChatID, `
+	    UPDATE registration_requests r
+		SET status = $1, rejection_reason = $2, claimed_by = NULL, claimed_at = NULL,
+		    updated_at = CURRENT_TIMESTAMP
+		FROM admins a
+		WHERE r.id = $3 AND a.id = $4
+		RETURNING a.chat_id
+	`, newStatus, rejectionReason, requestID, adminID)
 
 	if err != nil {
 		return fmt.Errorf("RegistrationRequestRepository.UpdateStatus; %w", err)
 	}
 
+	err = recordRequestEvent(tx, RequestEvent{
+		RequestID:   requestID,
+		ActorType:   ActorAdmin,
+		ActorChatID: adminChatID,
+		OldStatus:   &current.Status,
+		NewStatus:   newStatus,
+		Reason:      rejectionReason,
+		TemplateID:  templateID,
+	})
+	if err != nil {
+		return fmt.Errorf("RegistrationRequestRepository.UpdateStatus: cannot record event: %w", err)
+	}
+
+	if err := enqueueNotification(tx, newStatus, current.TelegramUserID, requestID, rejectionReason); err != nil {
+		return fmt.Errorf("RegistrationRequestRepository.UpdateStatus: cannot enqueue notification: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("RegistrationRequestRepository.UpdateStatus: %w", err)
+	}
+
+	return nil
+}
+
+// Отложить закреплённую за админом заявку с внутренней заметкой.
+// Если задан remindAt, в это время заявка вернётся к нему в работу
+func (r *RegistrationRequestRepository) Hold(requestID, adminID int64, note string, remindAt *time.Time) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("RegistrationRequestRepository.Hold: %w", err)
+	}
+	defer tx.Rollback()
+
+	current, err := lockRequest(tx, requestID)
+	if err != nil {
+		return fmt.Errorf("RegistrationRequestRepository.Hold: %w", err)
+	}
+
+	if err := lifecycle.Transition(current.Status, lifecycle.OnHold); err != nil {
+		return fmt.Errorf("RegistrationRequestRepository.Hold: %w", err)
+	}
+
+	if current.ClaimedBy == nil || *current.ClaimedBy != adminID {
+		return fmt.Errorf("RegistrationRequestRepository.Hold: %w", ErrRequestNotClaimed)
+	}
+
+	var adminChatID int64
+
+	err = tx.Get(&adminChatID, `
+	    UPDATE registration_requests r
+		SET status = $1, held_by = $2, hold_note = $3, remind_at = $4,
+		    claimed_by = NULL, claimed_at = NULL, updated_at = CURRENT_TIMESTAMP
+		FROM admins a
+		WHERE r.id = $5 AND a.id = $2
+		RETURNING a.chat_id
+	`, lifecycle.OnHold, adminID, note, remindAt, requestID)
+
+	if err != nil {
+		return fmt.Errorf("RegistrationRequestRepository.Hold: %w", err)
+	}
+
+	err = recordRequestEvent(tx, RequestEvent{
+		RequestID:   requestID,
+		ActorType:   ActorAdmin,
+		ActorChatID: adminChatID,
+		OldStatus:   &current.Status,
+		NewStatus:   lifecycle.OnHold,
+		Reason:      &note,
+	})
+	if err != nil {
+		return fmt.Errorf("RegistrationRequestRepository.Hold: cannot record event: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("RegistrationRequestRepository.Hold: %w", err)
+	}
+
 	return nil
 }
 
+// Вернуть отложенную заявку в работу: она сразу закрепляется за этим админом
+func (r *RegistrationRequestRepository) Resume(requestID, adminID int64) (*RegistrationRequest, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("RegistrationRequestRepository.Resume: %w", err)
+	}
+	defer tx.Rollback()
+
+	var adminChatID int64
+
+	err = tx.Get(&adminChatID, `SELECT chat_id FROM admins WHERE id = $1`, adminID)
+	if err != nil {
+		return nil, fmt.Errorf("RegistrationRequestRepository.Resume: %w", err)
+	}
+
+	req, err := resume(tx, requestID, &adminID, ActorAdmin, adminChatID)
+	if err != nil {
+		return nil, fmt.Errorf("RegistrationRequestRepository.Resume: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("RegistrationRequestRepository.Resume: %w", err)
+	}
+
+	return req, nil
+}
+
+// Вернуть в работу отложенные заявки, время напоминания которых наступило.
+// Каждая закрепляется за отложившим её админом
+func (r *RegistrationRequestRepository) ResumeDue() ([]RegistrationRequest, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("RegistrationRequestRepository.ResumeDue: %w", err)
+	}
+	defer tx.Rollback()
+
+	var due []RegistrationRequest
+
+	err = tx.Select(&due, `
+	    SELECT * FROM registration_requests
+		WHERE status = $1 AND remind_at <= CURRENT_TIMESTAMP
+		ORDER BY remind_at
+		FOR UPDATE SKIP LOCKED
+	`, lifecycle.OnHold)
+
+	if err != nil {
+		return nil, fmt.Errorf("RegistrationRequestRepository.ResumeDue: %w", err)
+	}
+
+	resumed := make([]RegistrationRequest, 0, len(due))
+	for _, held := range due {
+		req, err := resume(tx, held.ID, held.HeldBy, ActorSystem, 0)
+		if err != nil {
+			return nil, fmt.Errorf("RegistrationRequestRepository.ResumeDue: %w", err)
+		}
+		resumed = append(resumed, *req)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("RegistrationRequestRepository.ResumeDue: %w", err)
+	}
+
+	return resumed, nil
+}
+
+// Перевести заявку из on_hold в pending с закреплением за claimedBy.
+// held_by и заметка остаются, чтобы было видно, кто и зачем откладывал
+func resume(tx *sqlx.Tx, requestID int64, claimedBy *int64, actorType string, actorChatID int64) (*RegistrationRequest, error) {
+	current, err := lockRequest(tx, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := lifecycle.Transition(current.Status, lifecycle.Pending); err != nil {
+		return nil, err
+	}
+
+	var req RegistrationRequest
+
+	err = tx.Get(&req, `
+	    UPDATE registration_requests
+		SET status = $1, remind_at = NULL, claimed_by = $2,
+		    claimed_at = CASE WHEN $2::INT IS NULL THEN NULL ELSE CURRENT_TIMESTAMP END,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+		RETURNING *
+	`, lifecycle.Pending, claimedBy, requestID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = recordRequestEvent(tx, RequestEvent{
+		RequestID:   requestID,
+		ActorType:   actorType,
+		ActorChatID: actorChatID,
+		OldStatus:   &current.Status,
+		NewStatus:   lifecycle.Pending,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot record event: %w", err)
+	}
+
+	return &req, nil
+}
+
+// Текущее состояние заявки под блокировкой строки до конца транзакции
+func lockRequest(tx *sqlx.Tx, requestID int64) (*RegistrationRequest, error) {
+	var req RegistrationRequest
+
+	err := tx.Get(&req, `
+	    SELECT * FROM registration_requests
+		WHERE id = $1
+		FOR UPDATE
+	`, requestID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &req, nil
+}
+
 func (r *RegistrationRequestRepository) GetByID(requestID int64) (*RegistrationRequest, error) {
 	var req RegistrationRequest
 
@@ -130,29 +441,198 @@ func (r *RegistrationRequestRepository) GetByID(requestID int64) (*RegistrationR
 	return &req, nil
 }
 
-func (r *RegistrationRequestRepository) GetNextPending() (*RegistrationRequest, error) {
+// Закрепить за админом следующую заявку на проверку. Сначала возвращается
+// уже закреплённая за ним, затем самая старая свободная или с истёкшим
+// закреплением. skipID (0 — не пропускать) исключает заявку, от которой админ
+// только что перешёл к следующей. SKIP LOCKED не даёт двум админам взять одну заявку
+func (r *RegistrationRequestRepository) ClaimNextPending(adminID int64, ttl time.Duration, skipID int64) (*RegistrationRequest, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("RegistrationRequestRepository.ClaimNextPending: %w", err)
+	}
+	defer tx.Rollback()
+
+	var requestID int64
+
+	err = tx.Get(&requestID, `
+	    SELECT id FROM registration_requests
+		WHERE status = $4 AND id <> $3
+		  AND (claimed_by IS NULL OR claimed_by = $1 OR claimed_at < $2)
+		ORDER BY claimed_by IS NOT DISTINCT FROM $1 DESC, created_at ASC
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+	`, adminID, time.Now().Add(-ttl), skipID, lifecycle.Pending)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("RegistrationRequestRepository.ClaimNextPending: %w", err)
+	}
+
+	req, err := claim(tx, requestID, adminID)
+	if err != nil {
+		return nil, fmt.Errorf("RegistrationRequestRepository.ClaimNextPending: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("RegistrationRequestRepository.ClaimNextPending: %w", err)
+	}
+
+	return req, nil
+}
+
+// Закрепить конкретную заявку, например открытую из уведомления.
+// ErrRequestNotClaimed, если она уже рассмотрена или её проверяет другой админ
+func (r *RegistrationRequestRepository) Claim(requestID, adminID int64, ttl time.Duration) (*RegistrationRequest, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("RegistrationRequestRepository.Claim: %w", err)
+	}
+	defer tx.Rollback()
+
+	var lockedID int64
+
+	err = tx.Get(&lockedID, `
+	    SELECT id FROM registration_requests
+		WHERE id = $1 AND status = $4
+		  AND (claimed_by IS NULL OR claimed_by = $2 OR claimed_at < $3)
+		FOR UPDATE SKIP LOCKED
+	`, requestID, adminID, time.Now().Add(-ttl), lifecycle.Pending)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("RegistrationRequestRepository.Claim: %w", ErrRequestNotClaimed)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("RegistrationRequestRepository.Claim: %w", err)
+	}
+
+	req, err := claim(tx, lockedID, adminID)
+	if err != nil {
+		return nil, fmt.Errorf("RegistrationRequestRepository.Claim: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("RegistrationRequestRepository.Claim: %w", err)
+	}
+
+	return req, nil
+}
+
+func claim(tx *sqlx.Tx, requestID, adminID int64) (*RegistrationRequest, error) {
 	var req RegistrationRequest
 
-	query := `
-	    SELECT
-		    id, telegram_user_id, first_name, last_name, birth_date, user_status, document_path,
-			phone_number, status, rejection_reason, created_at, updated_at
+	err := tx.Get(&req, `
+	    UPDATE registration_requests
+		SET claimed_by = $1, claimed_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+		RETURNING *
+	`, adminID, requestID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &req, nil
+}
+
+// Снять закрепление, если админ вышел из заявки без решения
+func (r *RegistrationRequestRepository) Release(requestID, adminID int64) error {
+	_, err := r.db.Exec(`
+	    UPDATE registration_requests
+		SET claimed_by = NULL, claimed_at = NULL
+		WHERE id = $1 AND claimed_by = $2
+	`, requestID, adminID)
+
+	if err != nil {
+		return fmt.Errorf("RegistrationRequestRepository.Release: %w", err)
+	}
+
+	return nil
+}
+
+// RequestFilter — условия выборки заявок для списка в боте администратора.
+// Пустой UserStatus и нулевой CreatedSince не ограничивают выборку
+type RequestFilter struct {
+	Status       lifecycle.Status
+	UserStatus   string
+	CreatedSince time.Time
+}
+
+func (f RequestFilter) where() (string, []interface{}) {
+	conditions := []string{"status = $1"}
+	args := []interface{}{f.Status}
+
+	if f.UserStatus != "" {
+		args = append(args, f.UserStatus)
+		conditions = append(conditions, fmt.Sprintf("user_status = $%d", len(args)))
+	}
+
+	if !f.CreatedSince.IsZero() {
+		args = append(args, f.CreatedSince)
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+// Заявки по фильтру, сначала самые старые
+func (r *RegistrationRequestRepository) List(filter RequestFilter, page Page) ([]RegistrationRequest, error) {
+	var requests []RegistrationRequest
+
+	where, args := filter.where()
+	args = append(args, page.Limit, page.Offset)
+
+	err := r.db.Select(&requests, fmt.Sprintf(`
+	    SELECT * FROM registration_requests
+		WHERE %s
+		ORDER BY created_at, id
+		LIMIT $%d OFFSET $%d
+	`, where, len(args)-1, len(args)), args...)
+
+	if err != nil {
+		return nil, fmt.Errorf("RegistrationRequestRepository.List: %w", err)
+	}
+
+	return requests, nil
+}
+
+func (r *RegistrationRequestRepository) Count(filter RequestFilter) (int, error) {
+	var count int
+
+	where, args := filter.where()
+
+	err := r.db.Get(&count, `SELECT COUNT(*) FROM registration_requests WHERE `+where, args...)
+	if err != nil {
+		return 0, fmt.Errorf("RegistrationRequestRepository.Count: %w", err)
+	}
+
+	return count, nil
+}
+
+// Количество заявок в каждом статусе; статусов без заявок в ответе нет
+func (r *RegistrationRequestRepository) CountByStatus() (map[lifecycle.Status]int, error) {
+	var rows []struct {
+		Status lifecycle.Status `db:"status"`
+		Count  int              `db:"count"`
+	}
+
+	err := r.db.Select(&rows, `
+	    SELECT status, COUNT(*) AS count
 		FROM registration_requests
-		WHERE status = 'pending'
-		ORDER BY created_at ASC
-		LIMIT 1	
-	`
+		GROUP BY status
+	`)
 
-	err := r.db.Get(&req, query)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, nil
-		}
+		return nil, fmt.Errorf("RegistrationRequestRepository.CountByStatus: %w", err)
+	}
 
-		return nil, fmt.Errorf("RegistrationRequestRepository.GetNextPending: %w", err)
+	counts := make(map[lifecycle.Status]int, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
 	}
 
-	return &req, nil
+	return counts, nil
 }
 
 func (r *RegistrationRequestRepository) GetTelegramUserIDByRequest(requestID int64) (int64, error) {
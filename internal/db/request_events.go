@@ -0,0 +1,66 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/gratefultolord/ac_signup_bot/internal/lifecycle"
+)
+
+const (
+	ActorAdmin  = "admin"
+	ActorUser   = "user"
+	ActorSystem = "system"
+)
+
+// RequestEvent — одна смена статуса заявки. OldStatus пуст у создания заявки,
+// ActorChatID равен 0 у действий системы. TemplateID задан, если причина выбрана из шаблонов
+type RequestEvent struct {
+	ID          int64             `db:"id"`
+	RequestID   int64             `db:"request_id"`
+	ActorType   string            `db:"actor_type"`
+	ActorChatID int64             `db:"actor_chat_id"`
+	OldStatus   *lifecycle.Status `db:"old_status"`
+	NewStatus   lifecycle.Status  `db:"new_status"`
+	Reason      *string           `db:"reason"`
+	TemplateID  *int64            `db:"template_id"`
+	CreatedAt   time.Time         `db:"created_at"`
+}
+
+// Записывается в той же транзакции, что и сама смена статуса
+func recordRequestEvent(tx *sqlx.Tx, event RequestEvent) error {
+	_, err := tx.Exec(`
+	    INSERT INTO registration_request_events
+		(request_id, actor_type, actor_chat_id, old_status, new_status, reason, template_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`,
+		event.RequestID,
+		event.ActorType,
+		event.ActorChatID,
+		event.OldStatus,
+		event.NewStatus,
+		event.Reason,
+		event.TemplateID,
+	)
+
+	return err
+}
+
+// История заявки в хронологическом порядке
+func (r *RegistrationRequestRepository) GetEvents(requestID int64) ([]RequestEvent, error) {
+	var events []RequestEvent
+
+	err := r.db.Select(&events, `
+	    SELECT * FROM registration_request_events
+		WHERE request_id = $1
+		ORDER BY created_at, id
+	`, requestID)
+
+	if err != nil {
+		return nil, fmt.Errorf("RegistrationRequestRepository.GetEvents: %w", err)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,45 @@
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+// RequestNote — внутренняя заметка админа к заявке
+type RequestNote struct {
+	ID          int64     `db:"id"`
+	RequestID   int64     `db:"request_id"`
+	AdminChatID int64     `db:"admin_chat_id"`
+	Text        string    `db:"text"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+func (r *RegistrationRequestRepository) AddNote(requestID, adminChatID int64, text string) error {
+	_, err := r.db.Exec(`
+	    INSERT INTO registration_request_notes (request_id, admin_chat_id, text)
+		VALUES ($1, $2, $3)
+	`, requestID, adminChatID, text)
+
+	if err != nil {
+		return fmt.Errorf("RegistrationRequestRepository.AddNote: %w", err)
+	}
+
+	return nil
+}
+
+// Заметки к заявке в хронологическом порядке
+func (r *RegistrationRequestRepository) GetNotes(requestID int64) ([]RequestNote, error) {
+	var notes []RequestNote
+
+	err := r.db.Select(&notes, `
+	    SELECT * FROM registration_request_notes
+		WHERE request_id = $1
+		ORDER BY created_at, id
+	`, requestID)
+
+	if err != nil {
+		return nil, fmt.Errorf("RegistrationRequestRepository.GetNotes: %w", err)
+	}
+
+	return notes, nil
+}
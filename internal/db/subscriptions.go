@@ -0,0 +1,76 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type SubscriptionRepository struct {
+	db *sqlx.DB
+}
+
+func NewSubscriptionRepository(db *sqlx.DB) *SubscriptionRepository {
+	return &SubscriptionRepository{
+		db: db,
+	}
+}
+
+// Активные участники, у которых подписка заканчивается в ближайшие daysBefore дней
+// и которым ещё не отправляли напоминание за это или меньшее число дней
+func (r *SubscriptionRepository) GetDueReminders(daysBefore int) ([]User, error) {
+	var users []User
+
+	err := r.db.Select(&users, `
+	    SELECT u.* FROM users u
+		WHERE u.membership_status = 'active'
+		AND u.expires_at > CURRENT_TIMESTAMP
+		AND u.expires_at <= CURRENT_TIMESTAMP + make_interval(days => $1)
+		AND NOT EXISTS (
+		    SELECT 1 FROM subscription_reminders sr
+			WHERE sr.user_id = u.id
+			AND sr.expires_at = u.expires_at
+			AND sr.days_before <= $1
+		)
+	`, daysBefore)
+
+	if err != nil {
+		return nil, fmt.Errorf("SubscriptionRepository.GetDueReminders: %w", err)
+	}
+
+	return users, nil
+}
+
+func (r *SubscriptionRepository) MarkReminderSent(userID int64, expiresAt time.Time, daysBefore int) error {
+	_, err := r.db.Exec(`
+	    INSERT INTO subscription_reminders (user_id, expires_at, days_before)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, expires_at, days_before) DO NOTHING
+	`, userID, expiresAt, daysBefore)
+
+	if err != nil {
+		return fmt.Errorf("SubscriptionRepository.MarkReminderSent: %w", err)
+	}
+
+	return nil
+}
+
+// Пометить истёкшими подписки, у которых закончился льготный период
+func (r *SubscriptionRepository) ExpireLapsed(gracePeriod time.Duration) ([]User, error) {
+	var users []User
+
+	err := r.db.Select(&users, `
+	    UPDATE users
+		SET membership_status = 'expired', updated_at = CURRENT_TIMESTAMP
+		WHERE membership_status = 'active'
+		AND expires_at + make_interval(secs => $1) < CURRENT_TIMESTAMP
+		RETURNING *
+	`, gracePeriod.Seconds())
+
+	if err != nil {
+		return nil, fmt.Errorf("SubscriptionRepository.ExpireLapsed: %w", err)
+	}
+
+	return users, nil
+}
@@ -0,0 +1,294 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	ConversationOpen     = "open"
+	ConversationAnswered = "answered"
+	ConversationClosed   = "closed"
+
+	SenderUser  = "user"
+	SenderAdmin = "admin"
+)
+
+// ErrConversationClosed возвращается при ответе в уже закрытое обращение
+var ErrConversationClosed = errors.New("conversation is closed")
+
+type SupportConversation struct {
+	ID             int64     `db:"id"`
+	TelegramUserID int64     `db:"telegram_user_id"`
+	FirstName      string    `db:"first_name"`
+	LastName       string    `db:"last_name"`
+	Status         string    `db:"status"`
+	CreatedAt      time.Time `db:"created_at"`
+	UpdatedAt      time.Time `db:"updated_at"`
+}
+
+type SupportMessage struct {
+	ID             int64     `db:"id"`
+	ConversationID int64     `db:"conversation_id"`
+	Sender         string    `db:"sender"`
+	AdminChatID    *int64    `db:"admin_chat_id"`
+	Text           string    `db:"text"`
+	CreatedAt      time.Time `db:"created_at"`
+}
+
+type SupportRepository struct {
+	db *sqlx.DB
+}
+
+func NewSupportRepository(db *sqlx.DB) *SupportRepository {
+	return &SupportRepository{
+		db: db,
+	}
+}
+
+// Сообщение пользователя продолжает его незакрытое обращение или открывает новое
+func (r *SupportRepository) AddUserMessage(telegramUserID int64, firstName, lastName, text string) (*SupportConversation, *SupportMessage, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, nil, fmt.Errorf("SupportRepository.AddUserMessage: %w", err)
+	}
+	defer tx.Rollback()
+
+	var conversation SupportConversation
+
+	err = tx.Get(&conversation, `
+	    SELECT * FROM support_conversations
+		WHERE telegram_user_id = $1 AND status <> 'closed'
+		FOR UPDATE
+	`, telegramUserID)
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		err = tx.Get(&conversation, `
+		    INSERT INTO support_conversations (telegram_user_id, first_name, last_name)
+			VALUES ($1, $2, $3)
+			RETURNING *
+		`, telegramUserID, firstName, lastName)
+		if err != nil {
+			return nil, nil, fmt.Errorf("SupportRepository.AddUserMessage: cannot open conversation: %w", err)
+		}
+
+	case err != nil:
+		return nil, nil, fmt.Errorf("SupportRepository.AddUserMessage: %w", err)
+
+	default:
+		err = tx.Get(&conversation, `
+		    UPDATE support_conversations
+			SET status = 'open', updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1
+			RETURNING *
+		`, conversation.ID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("SupportRepository.AddUserMessage: %w", err)
+		}
+	}
+
+	var message SupportMessage
+
+	err = tx.Get(&message, `
+	    INSERT INTO support_messages (conversation_id, sender, text)
+		VALUES ($1, 'user', $2)
+		RETURNING *
+	`, conversation.ID, text)
+	if err != nil {
+		return nil, nil, fmt.Errorf("SupportRepository.AddUserMessage: cannot save message: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("SupportRepository.AddUserMessage: %w", err)
+	}
+
+	return &conversation, &message, nil
+}
+
+// Ответ администратора переводит обращение в статус answered
+func (r *SupportRepository) AddAdminReply(conversationID, adminChatID int64, text string) (*SupportConversation, *SupportMessage, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, nil, fmt.Errorf("SupportRepository.AddAdminReply: %w", err)
+	}
+	defer tx.Rollback()
+
+	var conversation SupportConversation
+
+	err = tx.Get(&conversation, `
+	    UPDATE support_conversations
+		SET status = 'answered', updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status <> 'closed'
+		RETURNING *
+	`, conversationID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, fmt.Errorf("SupportRepository.AddAdminReply: %w", ErrConversationClosed)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("SupportRepository.AddAdminReply: %w", err)
+	}
+
+	var message SupportMessage
+
+	err = tx.Get(&message, `
+	    INSERT INTO support_messages (conversation_id, sender, admin_chat_id, text)
+		VALUES ($1, 'admin', $2, $3)
+		RETURNING *
+	`, conversationID, adminChatID, text)
+	if err != nil {
+		return nil, nil, fmt.Errorf("SupportRepository.AddAdminReply: cannot save message: %w", err)
+	}
+
+	if err := enqueueSupportNotification(tx, NotificationSupportReply, &conversation, &message.ID); err != nil {
+		return nil, nil, fmt.Errorf("SupportRepository.AddAdminReply: cannot enqueue notification: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("SupportRepository.AddAdminReply: %w", err)
+	}
+
+	return &conversation, &message, nil
+}
+
+func (r *SupportRepository) Close(conversationID int64) (*SupportConversation, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("SupportRepository.Close: %w", err)
+	}
+	defer tx.Rollback()
+
+	var conversation SupportConversation
+
+	err = tx.Get(&conversation, `
+	    UPDATE support_conversations
+		SET status = 'closed', updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status <> 'closed'
+		RETURNING *
+	`, conversationID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("SupportRepository.Close: %w", ErrConversationClosed)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("SupportRepository.Close: %w", err)
+	}
+
+	if err := enqueueSupportNotification(tx, NotificationSupportClosed, &conversation, nil); err != nil {
+		return nil, fmt.Errorf("SupportRepository.Close: cannot enqueue notification: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("SupportRepository.Close: %w", err)
+	}
+
+	return &conversation, nil
+}
+
+func (r *SupportRepository) GetByID(conversationID int64) (*SupportConversation, error) {
+	var conversation SupportConversation
+
+	err := r.db.Get(&conversation, `
+	    SELECT * FROM support_conversations
+		WHERE id = $1
+	`, conversationID)
+
+	if err != nil {
+		return nil, fmt.Errorf("SupportRepository.GetByID: %w", err)
+	}
+
+	return &conversation, nil
+}
+
+func (r *SupportRepository) GetMessage(messageID int64) (*SupportMessage, error) {
+	var message SupportMessage
+
+	err := r.db.Get(&message, `
+	    SELECT * FROM support_messages
+		WHERE id = $1
+	`, messageID)
+
+	if err != nil {
+		return nil, fmt.Errorf("SupportRepository.GetMessage: %w", err)
+	}
+
+	return &message, nil
+}
+
+// Последнее обращение пользователя или nil, если он ещё не писал
+func (r *SupportRepository) GetLatestByTelegramUserID(telegramUserID int64) (*SupportConversation, error) {
+	var conversation SupportConversation
+
+	err := r.db.Get(&conversation, `
+	    SELECT * FROM support_conversations
+		WHERE telegram_user_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1
+	`, telegramUserID)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("SupportRepository.GetLatestByTelegramUserID: %w", err)
+	}
+
+	return &conversation, nil
+}
+
+// Обращения с указанным статусом, сначала давно ожидающие
+func (r *SupportRepository) ListByStatus(status string, page Page) ([]SupportConversation, error) {
+	var conversations []SupportConversation
+
+	err := r.db.Select(&conversations, `
+	    SELECT * FROM support_conversations
+		WHERE status = $1
+		ORDER BY updated_at, id
+		LIMIT $2 OFFSET $3
+	`, status, page.Limit, page.Offset)
+
+	if err != nil {
+		return nil, fmt.Errorf("SupportRepository.ListByStatus: %w", err)
+	}
+
+	return conversations, nil
+}
+
+func (r *SupportRepository) CountByStatus(status string) (int, error) {
+	var count int
+
+	err := r.db.Get(&count, `
+	    SELECT COUNT(*) FROM support_conversations
+		WHERE status = $1
+	`, status)
+
+	if err != nil {
+		return 0, fmt.Errorf("SupportRepository.CountByStatus: %w", err)
+	}
+
+	return count, nil
+}
+
+// Последние limit сообщений обращения в хронологическом порядке
+func (r *SupportRepository) GetMessages(conversationID int64, limit int) ([]SupportMessage, error) {
+	var messages []SupportMessage
+
+	err := r.db.Select(&messages, `
+	    SELECT * FROM (
+		    SELECT * FROM support_messages
+			WHERE conversation_id = $1
+			ORDER BY created_at DESC, id DESC
+			LIMIT $2
+		) latest
+		ORDER BY created_at, id
+	`, conversationID, limit)
+
+	if err != nil {
+		return nil, fmt.Errorf("SupportRepository.GetMessages: %w", err)
+	}
+
+	return messages, nil
+}
@@ -58,39 +58,70 @@ func (r *TokenRepository) GetByCode(code string) (*Token, error) {
 	return &token, nil
 }
 
+// Получить последний код, выданный на номер телефона
+func (r *TokenRepository) GetByPhoneAndCode(phoneNumber, code string) (*Token, error) {
+	var token Token
+	err := r.db.Get(&token, `
+        SELECT * FROM tokens
+        WHERE phone_number = $1 AND code = $2
+        ORDER BY created_at DESC
+        LIMIT 1
+    `, phoneNumber, code)
+	if err != nil {
+		return nil, fmt.Errorf("TokenRepository.GetByPhoneAndCode: %w", err)
+	}
+	return &token, nil
+}
+
+// Получить запись по выданному JWT
+func (r *TokenRepository) GetByToken(jwtToken string) (*Token, error) {
+	var token Token
+	err := r.db.Get(&token, `
+        SELECT * FROM tokens
+        WHERE token = $1
+    `, jwtToken)
+	if err != nil {
+		return nil, fmt.Errorf("TokenRepository.GetByToken: %w", err)
+	}
+	return &token, nil
+}
+
 // Обновить JWT токен по id (после верификации кода на сайте)
-func (r *TokenRepository) UpdateJWT(tokenID int64, jwtToken string) error {
+func (r *TokenRepository) UpdateJWT(tokenID int64, jwtToken string, expiresAt time.Time) error {
 	_, err := r.db.Exec(`
         UPDATE tokens
-        SET token = $1
-        WHERE id = $2
-    `, jwtToken, tokenID)
+        SET token = $1, expires_at = $2
+        WHERE id = $3
+    `, jwtToken, expiresAt, tokenID)
 	if err != nil {
 		return fmt.Errorf("TokenRepository.UpdateJWT: %w", err)
 	}
 	return nil
 }
 
-// Удалить токен по JWT
-func (r *TokenRepository) DeleteByToken(jwtToken string) error {
+// Завершить сессию по JWT. Запись с кодом входа остаётся: код выдаётся
+// один раз при регистрации и нужен для следующего входа
+func (r *TokenRepository) ClearToken(jwtToken string) error {
 	_, err := r.db.Exec(`
-        DELETE FROM tokens
+        UPDATE tokens
+        SET token = NULL, expires_at = NULL
         WHERE token = $1
     `, jwtToken)
 	if err != nil {
-		return fmt.Errorf("TokenRepository.DeleteByToken: %w", err)
+		return fmt.Errorf("TokenRepository.ClearToken: %w", err)
 	}
 	return nil
 }
 
-// Очистить устаревшие токены
-func (r *TokenRepository) DeleteExpiredTokens() error {
+// Сбросить истёкшие JWT, не удаляя коды входа
+func (r *TokenRepository) ClearExpiredTokens() error {
 	_, err := r.db.Exec(`
-        DELETE FROM tokens
+        UPDATE tokens
+        SET token = NULL, expires_at = NULL
         WHERE expires_at < NOW()
     `)
 	if err != nil {
-		return fmt.Errorf("TokenRepository.DeleteExpiredTokens: %w", err)
+		return fmt.Errorf("TokenRepository.ClearExpiredTokens: %w", err)
 	}
 	return nil
 }
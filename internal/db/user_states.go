@@ -0,0 +1,91 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type UserStateRecord struct {
+	ChatID    int64     `db:"chat_id"`
+	Step      string    `db:"step"`
+	Data      []byte    `db:"data"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+type UserStateRepository struct {
+	db *sqlx.DB
+}
+
+func NewUserStateRepository(db *sqlx.DB) *UserStateRepository {
+	return &UserStateRepository{
+		db: db,
+	}
+}
+
+// Получить сохранённое состояние чата, nil если его нет
+func (r *UserStateRepository) Get(chatID int64) (*UserStateRecord, error) {
+	var record UserStateRecord
+
+	err := r.db.Get(&record, `
+	    SELECT chat_id, step, data, updated_at
+		FROM user_states
+		WHERE chat_id = $1
+	`, chatID)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("UserStateRepository.Get: %w", err)
+	}
+
+	return &record, nil
+}
+
+func (r *UserStateRepository) GetAll() ([]UserStateRecord, error) {
+	var records []UserStateRecord
+
+	err := r.db.Select(&records, `
+	    SELECT chat_id, step, data, updated_at
+		FROM user_states
+	`)
+
+	if err != nil {
+		return nil, fmt.Errorf("UserStateRepository.GetAll: %w", err)
+	}
+
+	return records, nil
+}
+
+func (r *UserStateRepository) Save(chatID int64, step string, data []byte) error {
+	_, err := r.db.Exec(`
+	    INSERT INTO user_states (chat_id, step, data, updated_at)
+		VALUES ($1, $2, $3, CURRENT_TIMESTAMP)
+		ON CONFLICT (chat_id) DO UPDATE
+		SET step = EXCLUDED.step, data = EXCLUDED.data, updated_at = CURRENT_TIMESTAMP
+	`, chatID, step, data)
+
+	if err != nil {
+		return fmt.Errorf("UserStateRepository.Save: %w", err)
+	}
+
+	return nil
+}
+
+func (r *UserStateRepository) Delete(chatID int64) error {
+	_, err := r.db.Exec(`
+	    DELETE FROM user_states
+		WHERE chat_id = $1
+	`, chatID)
+
+	if err != nil {
+		return fmt.Errorf("UserStateRepository.Delete: %w", err)
+	}
+
+	return nil
+}
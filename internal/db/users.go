@@ -8,17 +8,18 @@ import (
 )
 
 type User struct {
-	ID             int64     `db:"id"`
-	TelegramUserID int64     `db:"telegram_user_id"`
-	FirstName      string    `db:"first_name"`
-	LastName       string    `db:"last_name"`
-	BirthDate      time.Time `db:"birth_date"`
-	Status         string    `db:"status"`
-	PhoneNumber    string    `db:"phone_number"`
-	PhotoPath      *string   `db:"photo_path"`
-	ExpiresAt      time.Time `db:"expires_at"`
-	CreatedAt      time.Time `db:"created_at"`
-	UpdatedAt      time.Time `db:"updated_at"`
+	ID               int64     `db:"id"`
+	TelegramUserID   int64     `db:"telegram_user_id"`
+	FirstName        string    `db:"first_name"`
+	LastName         string    `db:"last_name"`
+	BirthDate        time.Time `db:"birth_date"`
+	Status           string    `db:"status"`
+	PhoneNumber      string    `db:"phone_number"`
+	PhotoPath        *string   `db:"photo_path"`
+	ExpiresAt        time.Time `db:"expires_at"`
+	MembershipStatus string    `db:"membership_status"`
+	CreatedAt        time.Time `db:"created_at"`
+	UpdatedAt        time.Time `db:"updated_at"`
 }
 
 type UserShort struct {
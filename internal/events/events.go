@@ -0,0 +1,132 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Channel — канал Postgres, через который боты обмениваются событиями
+const Channel = "ac_signup_events"
+
+type Type string
+
+const (
+	// Из бота регистрации
+	RequestSubmitted Type = "request_submitted"
+
+	// Из бота администратора
+	RequestApproved    Type = "request_approved"
+	RequestRejected    Type = "request_rejected"
+	RevisionRequested  Type = "revision_requested"
+	ConversationClosed Type = "conversation_closed"
+
+	// Админ вернул недоставленное уведомление в очередь
+	NotificationRequeued Type = "notification_requeued"
+
+	// Из обоих: кто отправил, видно по SupportMessage.Sender
+	MessageSent Type = "message_sent"
+)
+
+// Event несёт только идентификаторы: получатель читает актуальные данные из базы,
+// а размер уведомления не упирается в лимит NOTIFY
+type Event struct {
+	Type           Type  `json:"type"`
+	RequestID      int64 `json:"request_id,omitempty"`
+	ConversationID int64 `json:"conversation_id,omitempty"`
+	MessageID      int64 `json:"message_id,omitempty"`
+}
+
+type Publisher struct {
+	db *sqlx.DB
+}
+
+func NewPublisher(db *sqlx.DB) *Publisher {
+	return &Publisher{
+		db: db,
+	}
+}
+
+func (p *Publisher) Publish(event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("Publisher.Publish: %w", err)
+	}
+
+	if _, err := p.db.Exec(`SELECT pg_notify($1, $2)`, Channel, string(payload)); err != nil {
+		return fmt.Errorf("Publisher.Publish: %w", err)
+	}
+
+	return nil
+}
+
+// Listener держит отдельное соединение с LISTEN и переподключается при обрыве
+type Listener struct {
+	listener *pq.Listener
+	events   chan Event
+}
+
+func Listen(dsn string) (*Listener, error) {
+	pqListener := pq.NewListener(dsn, 10*time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("events: listener error: %v", err)
+		}
+	})
+
+	if err := pqListener.Listen(Channel); err != nil {
+		pqListener.Close()
+		return nil, fmt.Errorf("events.Listen: %w", err)
+	}
+
+	l := &Listener{
+		listener: pqListener,
+		events:   make(chan Event, 64),
+	}
+
+	go l.run()
+
+	return l, nil
+}
+
+func (l *Listener) Events() <-chan Event {
+	return l.events
+}
+
+func (l *Listener) Close() error {
+	return l.listener.Close()
+}
+
+func (l *Listener) run() {
+	defer close(l.events)
+
+	for {
+		select {
+		case n, ok := <-l.listener.Notify:
+			if !ok {
+				return
+			}
+
+			// nil приходит после переподключения: уведомления за время обрыва потеряны
+			if n == nil {
+				log.Printf("events: listener reconnected")
+				continue
+			}
+
+			var event Event
+			if err := json.Unmarshal([]byte(n.Extra), &event); err != nil {
+				log.Printf("events: bad payload %q: %v", n.Extra, err)
+				continue
+			}
+
+			l.events <- event
+
+		case <-time.After(90 * time.Second):
+			// Проверяем соединение, если долго не было уведомлений
+			go l.listener.Ping()
+		}
+	}
+}
@@ -0,0 +1,75 @@
+// Package lifecycle описывает жизненный цикл заявки на регистрацию:
+// допустимые статусы и переходы между ними
+package lifecycle
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Status string
+
+const (
+	Pending       Status = "pending"
+	Approved      Status = "approved"
+	Rejected      Status = "rejected"
+	NeedsRevision Status = "needs_revision"
+	Paid          Status = "paid"
+	OnHold        Status = "on_hold"
+)
+
+// Из какого статуса в какие можно перейти. Rejected и Paid — конечные.
+// Отложенную заявку перед решением возвращают в Pending
+var transitions = map[Status][]Status{
+	Pending:       {Approved, Rejected, NeedsRevision, OnHold},
+	NeedsRevision: {Pending},
+	OnHold:        {Pending},
+	Approved:      {Paid},
+}
+
+// ErrInvalidTransition — общий признак недопустимого перехода для errors.Is
+var ErrInvalidTransition = errors.New("invalid request status transition")
+
+// TransitionError сообщает, какой именно переход был отклонён
+type TransitionError struct {
+	From Status
+	To   Status
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("cannot change request status from %q to %q", e.From, e.To)
+}
+
+func (e *TransitionError) Is(target error) bool {
+	return target == ErrInvalidTransition
+}
+
+func (s Status) Valid() bool {
+	switch s {
+	case Pending, Approved, Rejected, NeedsRevision, Paid, OnHold:
+		return true
+	}
+	return false
+}
+
+// Решение админа переводит заявку в один из этих статусов
+func (s Status) IsDecision() bool {
+	return s == Approved || s == Rejected || s == NeedsRevision
+}
+
+func CanTransition(from, to Status) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Transition возвращает *TransitionError, если перейти из from в to нельзя
+func Transition(from, to Status) error {
+	if !CanTransition(from, to) {
+		return &TransitionError{From: from, To: to}
+	}
+	return nil
+}
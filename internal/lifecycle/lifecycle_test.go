@@ -0,0 +1,91 @@
+package lifecycle
+
+import (
+	"errors"
+	"testing"
+)
+
+var allStatuses = []Status{Pending, Approved, Rejected, NeedsRevision, Paid, OnHold}
+
+func TestTransition(t *testing.T) {
+	allowed := map[Status][]Status{
+		Pending:       {Approved, Rejected, NeedsRevision, OnHold},
+		NeedsRevision: {Pending},
+		OnHold:        {Pending},
+		Approved:      {Paid},
+		Rejected:      nil,
+		Paid:          nil,
+	}
+
+	for _, from := range allStatuses {
+		for _, to := range allStatuses {
+			want := false
+			for _, next := range allowed[from] {
+				if next == to {
+					want = true
+				}
+			}
+
+			t.Run(string(from)+"->"+string(to), func(t *testing.T) {
+				if got := CanTransition(from, to); got != want {
+					t.Fatalf("CanTransition(%q, %q) = %v, want %v", from, to, got, want)
+				}
+
+				err := Transition(from, to)
+				if want {
+					if err != nil {
+						t.Fatalf("Transition(%q, %q) = %v, want nil", from, to, err)
+					}
+					return
+				}
+
+				if !errors.Is(err, ErrInvalidTransition) {
+					t.Fatalf("Transition(%q, %q) = %v, want ErrInvalidTransition", from, to, err)
+				}
+
+				var transitionErr *TransitionError
+				if !errors.As(err, &transitionErr) || transitionErr.From != from || transitionErr.To != to {
+					t.Fatalf("Transition(%q, %q) = %#v, want *TransitionError with both statuses", from, to, err)
+				}
+			})
+		}
+	}
+}
+
+func TestTransitionUnknownStatus(t *testing.T) {
+	if err := Transition("archived", Pending); !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("Transition from unknown status = %v, want ErrInvalidTransition", err)
+	}
+
+	if err := Transition(Pending, "archived"); !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("Transition to unknown status = %v, want ErrInvalidTransition", err)
+	}
+}
+
+func TestStatusValid(t *testing.T) {
+	for _, s := range allStatuses {
+		if !s.Valid() {
+			t.Errorf("%q.Valid() = false, want true", s)
+		}
+	}
+
+	for _, s := range []Status{"", "archived", "PENDING"} {
+		if s.Valid() {
+			t.Errorf("%q.Valid() = true, want false", s)
+		}
+	}
+}
+
+func TestStatusIsDecision(t *testing.T) {
+	decisions := map[Status]bool{
+		Approved:      true,
+		Rejected:      true,
+		NeedsRevision: true,
+	}
+
+	for _, s := range allStatuses {
+		if got := s.IsDecision(); got != decisions[s] {
+			t.Errorf("%q.IsDecision() = %v, want %v", s, got, decisions[s])
+		}
+	}
+}
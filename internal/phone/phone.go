@@ -0,0 +1,27 @@
+// Package phone приводит номера телефонов к виду 7XXXXXXXXXX, в котором
+// они хранятся в базе. Используется и ботом, и сервисом авторизации
+package phone
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	nonDigitRe = regexp.MustCompile(`\D`)
+	validRe    = regexp.MustCompile(`^7\d{10}$`)
+)
+
+func Normalize(raw string) string {
+	digitsOnly := nonDigitRe.ReplaceAllString(raw, "")
+
+	if strings.HasPrefix(digitsOnly, "8") {
+		digitsOnly = "7" + digitsOnly[1:]
+	}
+
+	return digitsOnly
+}
+
+func IsValid(phone string) bool {
+	return validRe.MatchString(phone)
+}
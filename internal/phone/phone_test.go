@@ -0,0 +1,42 @@
+package phone
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{raw: "+7 (999) 123-45-67", want: "79991234567"},
+		{raw: "8 999 123 45 67", want: "79991234567"},
+		{raw: "79991234567", want: "79991234567"},
+		{raw: "9991234567", want: "9991234567"},
+		{raw: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := Normalize(tt.raw); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestIsValid(t *testing.T) {
+	tests := []struct {
+		phone string
+		want  bool
+	}{
+		{phone: "79991234567", want: true},
+		{phone: "89991234567", want: false},
+		{phone: "7999123456", want: false},
+		{phone: "799912345678", want: false},
+		{phone: "+79991234567", want: false},
+		{phone: "", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := IsValid(tt.phone); got != tt.want {
+			t.Errorf("IsValid(%q) = %v, want %v", tt.phone, got, tt.want)
+		}
+	}
+}